@@ -7,8 +7,10 @@ const Actor := preload("res://objects/actor/actor.gd")
 @onready var _line_edit: LineEdit = $UI/LineEdit
 @onready var _log: Log = $UI/Log
 @onready var _world: Node2D = $World
+@onready var _player_list: Label = $UI/PlayerList
 
 var _players: Dictionary
+var _rtts: Dictionary
 
 func _ready() -> void:
 	WS.connection_closed.connect(_on_ws_connection_closed)
@@ -61,6 +63,11 @@ func _handle_player_msg(sender_id: int, player_msg: packets.PlayerMessage) -> vo
 		actor.position.x = x
 		actor.position.y = y
 
+	var rtt_ms := player_msg.get_rtt_ms()
+	if _rtts.get(actor_id) != rtt_ms:
+		_rtts[actor_id] = rtt_ms
+		_update_player_list()
+
 func _handle_id_msg(sender_id: int, id_msg: packets.IdMessage) -> void:
 	var player_id := id_msg.get_id()
 	if player_id in _players:
@@ -68,3 +75,13 @@ func _handle_id_msg(sender_id: int, id_msg: packets.IdMessage) -> void:
 		if _players[player_id] != null:
 			_players[player_id].queue_free()
 		_players.erase(player_id)
+		_rtts.erase(player_id)
+		_update_player_list()
+
+# Everyone in the game with their ping, as the server measures it
+func _update_player_list() -> void:
+	var lines := PackedStringArray()
+	for actor_id in _players:
+		var actor := _players[actor_id] as Actor
+		lines.append("%s  %d ms" % [actor.actor_name, _rtts.get(actor_id, 0)])
+	_player_list.text = "\n".join(lines)
@@ -64,7 +64,20 @@ func poll() -> void:
 		elif state == socket.STATE_CLOSED:
 			connection_closed.emit()
 	while socket.get_ready_state() == socket.STATE_OPEN and socket.get_available_packet_count():
-		packet_received.emit(get_packet())
+		var packet := get_packet()
+		if packet.has_ping():
+			_answer_ping(packet.get_ping())
+		else:
+			packet_received.emit(packet)
+
+
+# The server measures the round trip from our answers, and drops us if we
+# stop answering, whatever state we're in
+func _answer_ping(ping: packets.PingMessage) -> void:
+	var packet := packets.Packet.new()
+	var pong := packet.new_pong()
+	pong.set_timestamp(ping.get_timestamp())
+	send(packet)
 
 
 func _process(_delta: float) -> void:
@@ -0,0 +1,231 @@
+// Spawns a crowd of bots against a server and reports how it held up:
+//
+//	go run ./cmd/loadtest -bots 500 -duration 1m
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"os"
+	"server/pkg/bot"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	url        = flag.String("url", "ws://localhost:8080/ws", "websocket endpoint of the server")
+	metricsUrl = flag.String("metrics", "http://localhost:8080/metrics", "the server's metrics, for its dropped packet counts (empty to skip)")
+	bots       = flag.Int("bots", 100, "number of bots to spawn")
+	spawnRate  = flag.Float64("spawn-rate", 50, "bots spawned per second")
+	duration   = flag.Duration("duration", 30*time.Second, "how long each bot moves around for")
+	inputRate  = flag.Float64("input-rate", 20, "direction inputs each bot sends per second")
+	scriptName = flag.String("script", "wander", "movement script: wander, circle or straight")
+	seed       = flag.Int64("seed", 0, "seed for the bots' random movement, 0 for the current time")
+	password   = flag.String("password", "loadtest", "password to register the bots with")
+)
+
+// Setting up a bot shouldn't take longer than this
+const setupTimeout = 30 * time.Second
+
+// How far a bot got, and what it saw
+type result struct {
+	connected bool
+	loggedIn  bool
+
+	// Set if the server dropped the bot before it was done
+	disconnected bool
+
+	err    error
+	moving time.Duration
+	stats  bot.Stats
+}
+
+func main() {
+	flag.Parse()
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	if _, err := newScript(*scriptName, rand.New(rand.NewSource(0))); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	before, err := scrapeDroppedSends(*metricsUrl)
+	if err != nil {
+		slog.Warn("Failed to read the server's metrics", "error", err)
+	}
+
+	fmt.Printf("Spawning %d bots against %s (seed %d)\n", *bots, *url, *seed)
+	// Bot names have to be unique across runs against the same database
+	prefix := fmt.Sprintf("lt%d", time.Now().Unix()%100000)
+	results := make([]result, *bots)
+	var wg sync.WaitGroup
+	spawnEvery := time.Duration(float64(time.Second) / *spawnRate)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(*seed + int64(i)))
+			results[i] = runBot(fmt.Sprintf("%s_%d", prefix, i), rng)
+		}()
+		time.Sleep(spawnEvery)
+	}
+	wg.Wait()
+
+	after, err := scrapeDroppedSends(*metricsUrl)
+	if err != nil {
+		slog.Warn("Failed to read the server's metrics", "error", err)
+	}
+	report(results, before, after)
+}
+
+func newScript(name string, rng *rand.Rand) (bot.Script, error) {
+	switch name {
+	case "wander":
+		return bot.Wander(rng, 2*time.Second), nil
+	case "circle":
+		return bot.Circle(time.Duration(5+rng.Intn(5)) * time.Second), nil
+	case "straight":
+		return bot.Straight(rng.Float64() * 6.28), nil
+	}
+	return nil, fmt.Errorf("unknown script %q, expected wander, circle or straight", name)
+}
+
+func runBot(username string, rng *rand.Rand) result {
+	var r result
+	setupCtx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+
+	b, err := bot.Dial(setupCtx, *url)
+	if err != nil {
+		r.err = fmt.Errorf("connecting: %w", err)
+		return r
+	}
+	defer b.Close()
+	r.connected = true
+
+	if err := b.Register(setupCtx, username, *password); err != nil {
+		r.err = fmt.Errorf("registering: %w", err)
+		return r
+	}
+	if err := b.Login(setupCtx, username, *password); err != nil {
+		r.err = fmt.Errorf("logging in: %w", err)
+		return r
+	}
+	r.loggedIn = true
+
+	script, _ := newScript(*scriptName, rng)
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+	start := time.Now()
+	err = b.Run(ctx, script, time.Duration(float64(time.Second) / *inputRate))
+	r.moving = time.Since(start)
+	if err != nil {
+		r.disconnected = errors.Is(err, bot.ErrClosed)
+		r.err = fmt.Errorf("moving: %w", err)
+	}
+
+	// Give the last inputs a chance to be acknowledged
+	time.Sleep(500 * time.Millisecond)
+	r.stats = b.Stats()
+	return r
+}
+
+func report(results []result, droppedBefore, droppedAfter map[string]float64) {
+	var connected, loggedIn, disconnected int
+	var total bot.Stats
+	var ownRate, otherRate float64
+	errorCounts := make(map[string]int)
+	for _, r := range results {
+		if r.connected {
+			connected++
+		}
+		if r.loggedIn {
+			loggedIn++
+		}
+		if r.disconnected {
+			disconnected++
+		}
+		if r.err != nil {
+			errorCounts[r.err.Error()]++
+		}
+
+		s := r.stats
+		total.PacketsSent += s.PacketsSent
+		total.PacketsReceived += s.PacketsReceived
+		total.InputsSent += s.InputsSent
+		total.LastAcked += s.LastAcked
+		total.RttSamples += s.RttSamples
+		total.RttTotal += s.RttTotal
+		total.RttMax = max(total.RttMax, s.RttMax)
+		if seconds := r.moving.Seconds(); seconds > 0 {
+			ownRate += float64(s.OwnUpdates) / seconds
+			otherRate += float64(s.OtherUpdates) / seconds
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Connected:     %d/%d\n", connected, len(results))
+	fmt.Printf("Logged in:     %d/%d\n", loggedIn, len(results))
+	fmt.Printf("Disconnected:  %d while moving\n", disconnected)
+	for err, count := range errorCounts {
+		fmt.Printf("  %dx %s\n", count, err)
+	}
+	fmt.Printf("RTT:           avg %v, max %v over %d pings\n",
+		total.AverageRtt().Round(time.Microsecond), total.RttMax.Round(time.Microsecond), total.RttSamples)
+	if loggedIn > 0 {
+		fmt.Printf("Updates:       %.1f/s own, %.1f/s others, per bot\n", ownRate/float64(loggedIn), otherRate/float64(loggedIn))
+	}
+	fmt.Printf("Packets:       %d sent, %d received\n", total.PacketsSent, total.PacketsReceived)
+	fmt.Printf("Inputs:        %d sent, %d never applied\n", total.InputsSent, total.InputsSent-uint64(total.LastAcked))
+	if droppedBefore != nil && droppedAfter != nil {
+		var reasons []string
+		for reason, count := range droppedAfter {
+			reasons = append(reasons, fmt.Sprintf("%s %.0f", reason, count-droppedBefore[reason]))
+		}
+		fmt.Printf("Server drops:  %s\n", strings.Join(reasons, ", "))
+	}
+}
+
+// Reads radius_rumble_dropped_sends_total from the server, by reason
+func scrapeDroppedSends(url string) (map[string]float64, error) {
+	if url == "" {
+		return nil, nil
+	}
+	response, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metrics returned %s", response.Status)
+	}
+
+	const prefix = `radius_rumble_dropped_sends_total{reason="`
+	dropped := make(map[string]float64)
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		line, found := strings.CutPrefix(scanner.Text(), prefix)
+		if !found {
+			continue
+		}
+		reason, value, found := strings.Cut(line, `"} `)
+		if !found {
+			continue
+		}
+		count, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q: %w", scanner.Text(), err)
+		}
+		dropped[reason] = count
+	}
+	return dropped, scanner.Err()
+}
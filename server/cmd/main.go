@@ -1,35 +1,146 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	"server/internal/server"
+	"server/internal/server/admin"
 	"server/internal/server/clients"
+	"server/internal/server/config"
+	"server/internal/server/metrics"
+	"syscall"
 )
 
 var (
-	port = flag.String("port", "8080", "port to run the server on")
+	configPath = flag.String("config", "", "path to a JSON config file, see config.example.json")
+	port       = flag.Int("port", 0, "port to run the server on, overrides the config")
+	dbPath     = flag.String("db", "", "path to the SQLite database, overrides the config")
 )
 
 func main() {
 	flag.Parse()
-	hub := server.NewHub()
+	cfg, err := loadConfig()
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(server.NewLogger(cfg.Server, os.Stderr))
+
+	switch command := flag.Arg(0); command {
+	case "":
+	case "migrate":
+		os.Exit(runMigrate(cfg, flag.Args()[1:]))
+	default:
+		slog.Error("Unknown command, expected migrate or nothing", "command", command)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	hub := server.NewHub(cfg)
+	hub.UseBots(clients.NewBotClient)
 
 	// Start the hub first
 	go hub.Run()
-	log.Println("Hub is running and ready to accept connections")
+	slog.Info("Hub is starting")
 
-	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		hub.Serve(clients.NewWebSocketClient, w, r)
 	})
+	metrics.Default.Register(metrics.NewGaugeFunc("radius_rumble_clients_connected", "Clients connected to the hub", func() float64 {
+		return float64(hub.Clients.Len())
+	}))
+	mux.Handle("/metrics", metrics.Handler(metrics.Default))
+	mux.HandleFunc("/healthz", hub.ServeHealth)
+	mux.HandleFunc("/readyz", hub.ServeReady)
+	if cfg.Server.AdminToken != "" {
+		mux.Handle("/admin/", admin.NewHandler(hub, cfg.Server.AdminToken))
+	} else {
+		slog.Info("No admin token configured, the admin API is disabled")
+	}
 
 	// Start the server
-	addr := fmt.Sprintf(":%s", *port)
-	fmt.Printf("Server is listening on %s\n", addr)
-	err := http.ListenAndServe(addr, nil)
+	addr := fmt.Sprintf(":%d", cfg.Server.Port)
+	httpServer := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		slog.Info("Server is listening", "addr", addr)
+		err := httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Failed to start server", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Reload the gameplay tuning from the config file on SIGHUP
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			reloadTuning(hub)
+		}
+	}()
+
+	<-ctx.Done()
+	// A second signal kills the process straight away
+	stop()
+
+	slog.Info("Received shutdown signal, stopping the server")
+
+	// Keep serving HTTP during the countdown, so /readyz can report that we're
+	// draining. The hub turns new players away in the meantime.
+	countdown := cfg.Server.ShutdownCountdown.Duration
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), countdown+cfg.Server.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := hub.Shutdown(shutdownCtx, countdown); err != nil {
+		slog.Error("Failed to shut down cleanly", "error", err)
+		os.Exit(1)
+	}
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Failed to stop the HTTP server", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// Reads the config file and environment, then applies any flags that were set
+// on the command line, which take precedence over everything else
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "db":
+			cfg.Server.DatabasePath = *dbPath
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+func reloadTuning(hub *server.Hub) {
+	slog.Info("Reloading game tuning")
+	cfg, err := loadConfig()
 	if err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+		slog.Warn("Not reloading, invalid configuration", "error", err)
+		return
+	}
+	if err := hub.ReloadTuning(cfg.Game); err != nil {
+		slog.Warn("Not reloading, invalid game tuning", "error", err)
+		return
+	}
+	if cfg.Server != hub.Config.Server || cfg.Network != hub.Config.Network {
+		slog.Warn("Only the game section is reloaded, restart the server to apply other changes")
 	}
 }
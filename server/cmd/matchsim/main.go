@@ -0,0 +1,224 @@
+// Plays out a ranked queue with simulated players, to see what the
+// matchmaking settings in the config mean for waiting times, how even the
+// matches are and how well ratings find each player's skill:
+//
+//	go run ./cmd/matchsim -players 300 -duration 4h
+//	RR_MATCHMAKING_RATING_GAP_GROWTH=2 go run ./cmd/matchsim
+//
+// Each player has a hidden skill. They queue, play a match that lasts the
+// configured match_duration, take a break and queue again. They place in a
+// match by their skill plus some luck.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"server/internal/server/config"
+	"server/internal/server/matchmaking"
+	"slices"
+	"time"
+)
+
+var (
+	configPath  = flag.String("config", "", "path to a JSON config file, see config.example.json")
+	players     = flag.Int("players", 200, "number of simulated players")
+	duration    = flag.Duration("duration", 2*time.Hour, "how much time to simulate")
+	breakTime   = flag.Duration("break", 2*time.Minute, "average time players take between matches")
+	skillSpread = flag.Float64("skill-spread", 300, "standard deviation of the players' hidden skill")
+	luck        = flag.Float64("luck", 150, "standard deviation of how far a player's performance in a match strays from their skill")
+	seed        = flag.Int64("seed", 1, "seed for the simulation")
+)
+
+type player struct {
+	skill  float64
+	rating matchmaking.Rating
+
+	// When they next join the queue, unless they're in it
+	returns time.Time
+	queued  bool
+	matches int
+}
+
+type stats struct {
+	matches     int
+	bySize      map[int]int
+	waits       []time.Duration
+	ratingGaps  []float64
+	skillGaps   []float64
+	unmatched   int
+	longestWait time.Duration
+
+	// Between the ratings and the hidden skills of players with a few matches
+	correlation float64
+	ratingError float64
+}
+
+func main() {
+	flag.Parse()
+	cfg, err := config.Load(*configPath)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *players < 2 || *duration <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s := simulate(cfg.Matchmaking, rand.New(rand.NewSource(*seed)))
+	s.print(cfg.Matchmaking)
+}
+
+func simulate(cfg config.MatchmakingConfig, rng *rand.Rand) *stats {
+	settings, ratings := matchmaking.FromConfig(cfg)
+	queue := matchmaking.NewQueue(settings)
+
+	start := time.Unix(0, 0)
+	population := make([]*player, *players)
+	for i := range population {
+		population[i] = &player{
+			skill:   cfg.InitialRating + rng.NormFloat64()**skillSpread,
+			rating:  ratings.New(),
+			returns: start.Add(randomBreak(rng)),
+		}
+	}
+
+	s := &stats{bySize: make(map[int]int)}
+	for now := start; now.Before(start.Add(*duration)); now = now.Add(cfg.Interval.Duration) {
+		for id, p := range population {
+			if !p.queued && !now.Before(p.returns) {
+				p.queued = true
+				queue.Add(matchmaking.Ticket{Id: uint64(id), Rating: p.rating.Rating, Joined: now})
+			}
+		}
+
+		for _, group := range queue.Match(now) {
+			s.record(group, population, now)
+			play(group, population, ratings, rng)
+			for _, ticket := range group {
+				p := population[ticket.Id]
+				p.queued = false
+				p.returns = now.Add(cfg.MatchDuration.Duration + randomBreak(rng))
+			}
+		}
+	}
+
+	s.unmatched = queue.Len()
+	for _, ticket := range queue.Tickets() {
+		s.longestWait = max(s.longestWait, start.Add(*duration).Sub(ticket.Joined))
+	}
+	s.calibration(population)
+	return s
+}
+
+func randomBreak(rng *rand.Rand) time.Duration {
+	return time.Duration(rng.ExpFloat64() * float64(*breakTime))
+}
+
+// Places the group by how well each of them played, and updates their ratings
+func play(group []matchmaking.Ticket, population []*player, ratings matchmaking.RatingSettings, rng *rand.Rand) {
+	scores := make([]float64, len(group))
+	for i, ticket := range group {
+		scores[i] = population[ticket.Id].skill + rng.NormFloat64()**luck
+	}
+	places := matchmaking.Places(scores)
+
+	results := make([]matchmaking.Result, len(group))
+	for i, ticket := range group {
+		results[i] = matchmaking.Result{Rating: population[ticket.Id].rating, Place: places[i]}
+	}
+	for i, rating := range ratings.Update(results) {
+		p := population[group[i].Id]
+		p.rating = rating
+		p.matches++
+	}
+}
+
+func (s *stats) record(group []matchmaking.Ticket, population []*player, now time.Time) {
+	s.matches++
+	s.bySize[len(group)]++
+
+	minRating, maxRating := math.Inf(1), math.Inf(-1)
+	minSkill, maxSkill := math.Inf(1), math.Inf(-1)
+	for _, ticket := range group {
+		wait := now.Sub(ticket.Joined)
+		s.waits = append(s.waits, wait)
+		s.longestWait = max(s.longestWait, wait)
+
+		minRating, maxRating = math.Min(minRating, ticket.Rating), math.Max(maxRating, ticket.Rating)
+		skill := population[ticket.Id].skill
+		minSkill, maxSkill = math.Min(minSkill, skill), math.Max(maxSkill, skill)
+	}
+	s.ratingGaps = append(s.ratingGaps, maxRating-minRating)
+	s.skillGaps = append(s.skillGaps, maxSkill-minSkill)
+}
+
+// How well the ratings line up with the hidden skills, over players who have
+// played at least a few matches
+func (s *stats) calibration(population []*player) {
+	var skills, ratings []float64
+	for _, p := range population {
+		if p.matches >= 5 {
+			skills = append(skills, p.skill)
+			ratings = append(ratings, p.rating.Rating)
+		}
+	}
+	if len(skills) < 2 {
+		return
+	}
+
+	meanSkill, meanRating := mean(skills), mean(ratings)
+	var covariance, skillVariance, ratingVariance, squaredError float64
+	for i := range skills {
+		ds, dr := skills[i]-meanSkill, ratings[i]-meanRating
+		covariance += ds * dr
+		skillVariance += ds * ds
+		ratingVariance += dr * dr
+		squaredError += (skills[i] - ratings[i]) * (skills[i] - ratings[i])
+	}
+	s.correlation = covariance / math.Sqrt(skillVariance*ratingVariance)
+	s.ratingError = math.Sqrt(squaredError / float64(len(skills)))
+}
+
+func (s *stats) print(cfg config.MatchmakingConfig) {
+	fmt.Printf("Settings:    %d players a match (%d after %v), gap %.0f growing %.0f/s up to %.0f\n",
+		cfg.MatchSize, cfg.MinMatchSize, cfg.MaxWait.Duration, cfg.InitialRatingGap, cfg.RatingGapGrowth, cfg.MaxRatingGap)
+	fmt.Printf("Simulated:   %d players over %v\n", *players, *duration)
+	fmt.Printf("Matches:     %d\n", s.matches)
+	sizes := make([]int, 0, len(s.bySize))
+	for size := range s.bySize {
+		sizes = append(sizes, size)
+	}
+	slices.Sort(sizes)
+	for _, size := range sizes {
+		fmt.Printf("  %d players  %d\n", size, s.bySize[size])
+	}
+	if s.matches == 0 {
+		fmt.Printf("Still waiting: %d\n", s.unmatched)
+		return
+	}
+
+	slices.Sort(s.waits)
+	fmt.Printf("Wait:        median %v, 90th percentile %v, longest %v\n",
+		s.waits[len(s.waits)/2].Round(time.Second), s.waits[len(s.waits)*9/10].Round(time.Second), s.longestWait.Round(time.Second))
+	fmt.Printf("Still waiting at the end: %d\n", s.unmatched)
+	fmt.Printf("Rating gap:  %.0f on average between the best and worst rated in a match\n", mean(s.ratingGaps))
+	fmt.Printf("Skill gap:   %.0f on average between the best and worst player in a match\n", mean(s.skillGaps))
+	if s.correlation != 0 {
+		fmt.Printf("Ratings:     %.2f correlation with skill, %.0f off on average\n", s.correlation, s.ratingError)
+	}
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
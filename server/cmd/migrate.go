@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"server/internal/server/config"
+	"server/internal/server/db"
+	"server/internal/server/store"
+	"slices"
+)
+
+// Handles `server migrate` (apply pending migrations) and `server migrate
+// status` (list them without applying anything). Returns the exit code.
+func runMigrate(cfg *config.Config, args []string) int {
+	if cfg.Server.Storage != "sqlite" {
+		fmt.Printf("Nothing to migrate with %s storage\n", cfg.Server.Storage)
+		return 0
+	}
+
+	conn, err := store.OpenSqliteDb(cfg.Server.DatabasePath)
+	if err != nil {
+		slog.Error("Failed to open the database", "path", cfg.Server.DatabasePath, "error", err)
+		return 1
+	}
+	defer conn.Close()
+	ctx := context.Background()
+
+	switch {
+	case len(args) == 0:
+		migrations, err := db.Migrate(ctx, conn)
+		for _, m := range migrations {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			slog.Error("Failed to migrate the database", "error", err)
+			return 1
+		}
+		if len(migrations) == 0 {
+			fmt.Println("Database is up to date")
+		}
+		return 0
+	case len(args) == 1 && args[0] == "status":
+		return printMigrationStatus(ctx, conn)
+	}
+	slog.Error("Usage: server [flags] migrate [status]")
+	return 2
+}
+
+func printMigrationStatus(ctx context.Context, conn *sql.DB) int {
+	migrations, err := db.Migrations()
+	if err != nil {
+		slog.Error("Failed to load migrations", "error", err)
+		return 1
+	}
+	applied, err := db.AppliedVersions(ctx, conn)
+	if err != nil {
+		slog.Error("Failed to read applied migrations", "error", err)
+		return 1
+	}
+
+	for _, m := range migrations {
+		status := "pending"
+		if slices.Contains(applied, m.Version) {
+			status = "applied"
+		}
+		fmt.Printf("%04d_%s\t%s\n", m.Version, m.Name, status)
+	}
+	return 0
+}
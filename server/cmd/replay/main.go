@@ -0,0 +1,411 @@
+// Summarises a replay recorded by the server (see server.replay_dir), and can
+// serve it to game clients as if it were live:
+//
+//	go run ./cmd/replay -serve :8090 game.replay
+//
+// Clients connect to /ws as usual. Logging in with any username and password
+// starts the replay from the beginning.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"server/pkg/packets"
+	"server/pkg/replay"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	serveAddr = flag.String("serve", "", "address to serve the replay on, e.g. :8090 (empty to only print the summary)")
+	speed     = flag.Float64("speed", 1, "playback speed, 2 plays twice as fast")
+	loop      = flag.Bool("loop", false, "start again from the beginning when the replay ends")
+)
+
+const writeWait = 10 * time.Second
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <replay file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *speed <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	summary, err := summarise(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	summary.print()
+
+	if *serveAddr == "" {
+		return
+	}
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			slog.Warn("Failed to upgrade connection", "remote_addr", r.RemoteAddr, "error", err)
+			return
+		}
+		// Viewers get an id nobody in the replay has, so they aren't mistaken for a player
+		newViewer(conn, path, summary.maxId+1).run()
+	})
+	slog.Info("Serving the replay", "addr", *serveAddr, "speed", *speed)
+	if err := http.ListenAndServe(*serveAddr, nil); err != nil {
+		slog.Error("Failed to serve", "error", err)
+		os.Exit(1)
+	}
+}
+
+type summary struct {
+	header    replay.Header
+	ticks     uint64
+	length    time.Duration
+	retimed   []replay.Record
+	records   int
+	byType    map[string]int
+	names     map[uint64]string
+	peak      int
+	maxId     uint64
+	truncated bool
+}
+
+// Reads the whole replay, tallying what's in it
+func summarise(path string) (*summary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := replay.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	s := &summary{
+		header: reader.Header(),
+		byType: make(map[string]int),
+		names:  make(map[uint64]string),
+	}
+	present := make(map[uint64]bool)
+	timeline := newTimeline(s.header.TickInterval)
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			s.truncated = true
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		s.ticks = max(s.ticks, record.Tick)
+		if record.Tuning != nil {
+			timeline.change(record.Tick, record.Tuning.TickInterval)
+			s.retimed = append(s.retimed, record)
+			continue
+		}
+		s.records++
+		s.byType[packets.MsgName(record.Packet.Msg)]++
+		s.maxId = max(s.maxId, record.Packet.SenderId)
+		switch msg := record.Packet.Msg.(type) {
+		case *packets.Packet_Player:
+			s.names[msg.Player.Id] = msg.Player.Name
+			s.maxId = max(s.maxId, msg.Player.Id)
+			present[msg.Player.Id] = true
+			s.peak = max(s.peak, len(present))
+		case *packets.Packet_Id:
+			delete(present, msg.Id.Id)
+		}
+	}
+	s.length = timeline.when(s.ticks)
+	return s, nil
+}
+
+func (s *summary) print() {
+	fmt.Printf("Started:   %v (seed %d)\n", s.header.StartedAt.Local().Format(time.DateTime), s.header.Seed)
+	fmt.Printf("Length:    %v over %d ticks of %v\n", s.length, s.ticks, s.header.TickInterval)
+	for _, record := range s.retimed {
+		fmt.Printf("           then ticks of %v from tick %d\n", record.Tuning.TickInterval, record.Tick)
+	}
+	fmt.Printf("Map:       %.0fx%.0f\n", s.header.MapSize, s.header.MapSize)
+	fmt.Printf("Players:   %d, at most %d at once\n", len(s.names), s.peak)
+
+	names := make([]string, 0, len(s.names))
+	for id, name := range s.names {
+		names = append(names, fmt.Sprintf("%s (%d)", name, id))
+	}
+	slices.Sort(names)
+	if len(names) > 0 {
+		fmt.Printf("           %s\n", strings.Join(names, ", "))
+	}
+
+	fmt.Printf("Packets:   %d\n", s.records)
+	types := make([]string, 0, len(s.byType))
+	for name := range s.byType {
+		types = append(types, name)
+	}
+	slices.Sort(types)
+	for _, name := range types {
+		fmt.Printf("  %-16s %d\n", name, s.byType[name])
+	}
+	if s.truncated {
+		fmt.Println("The replay ends part way through a packet, the server probably didn't stop cleanly")
+	}
+}
+
+// When each tick started, counting from the start of the replay. Ticks are as
+// long as the header says until a Tuning record changes that.
+type timeline struct {
+	// The tick of the last change, when it started and how long ticks have
+	// been since
+	tick     uint64
+	at       time.Duration
+	interval time.Duration
+}
+
+func newTimeline(interval time.Duration) *timeline {
+	return &timeline{interval: interval}
+}
+
+// Ticks are the given length from the tick on
+func (t *timeline) change(tick uint64, interval time.Duration) {
+	t.at = t.when(tick)
+	t.tick = tick
+	t.interval = interval
+}
+
+func (t *timeline) when(tick uint64) time.Duration {
+	if tick < t.tick {
+		return t.at
+	}
+	return t.at + time.Duration(tick-t.tick)*t.interval
+}
+
+// A client watching the replay. It's treated like a player who has just
+// connected: told its id, and let in as soon as it logs in.
+type viewer struct {
+	conn   *websocket.Conn
+	path   string
+	id     uint64
+	send   chan *packets.Packet
+	start  chan struct{}
+	done   chan struct{}
+	logger *slog.Logger
+}
+
+func newViewer(conn *websocket.Conn, path string, id uint64) *viewer {
+	return &viewer{
+		conn:   conn,
+		path:   path,
+		id:     id,
+		send:   make(chan *packets.Packet, 64),
+		start:  make(chan struct{}),
+		done:   make(chan struct{}),
+		logger: slog.Default().With("remote_addr", conn.RemoteAddr()),
+	}
+}
+
+func (v *viewer) run() {
+	v.logger.Info("Viewer connected")
+	defer v.logger.Info("Viewer disconnected")
+	defer v.conn.Close()
+
+	go v.readPump()
+	go v.play()
+
+	v.send <- &packets.Packet{Msg: packets.NewId(v.id)}
+	for {
+		select {
+		case packet := <-v.send:
+			if err := v.write(packet); err != nil {
+				v.logger.Debug("Failed to write packet", "error", err)
+				return
+			}
+		case <-v.done:
+			return
+		}
+	}
+}
+
+func (v *viewer) write(packet *packets.Packet) error {
+	data, err := proto.Marshal(packet)
+	if err != nil {
+		return err
+	}
+	v.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	// Same framing as the game server
+	return v.conn.WriteMessage(websocket.BinaryMessage, append(data, '\n'))
+}
+
+// Answers logins, registrations and pings, and notices when the viewer leaves
+func (v *viewer) readPump() {
+	defer close(v.done)
+
+	started := false
+	for {
+		_, data, err := v.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		packet := &packets.Packet{}
+		if err := proto.Unmarshal(data, packet); err != nil {
+			v.logger.Debug("Ignoring malformed packet", "error", err)
+			continue
+		}
+
+		var reply packets.Msg
+		switch msg := packet.Msg.(type) {
+		case *packets.Packet_RegisterRequest:
+			reply = packets.NewOkResponse()
+		case *packets.Packet_LoginRequest:
+			reply = packets.NewOkResponse()
+			if !started {
+				started = true
+				close(v.start)
+			}
+		case *packets.Packet_Ping:
+			reply = packets.NewPong(msg.Ping.Timestamp)
+		default:
+			continue
+		}
+		select {
+		case v.send <- &packets.Packet{Msg: reply}:
+		case <-v.done:
+			return
+		}
+	}
+}
+
+// Sends each record once its tick comes round, after the viewer has logged in
+func (v *viewer) play() {
+	select {
+	case <-v.start:
+	case <-v.done:
+		return
+	}
+
+	for {
+		world, err := v.playOnce()
+		if err != nil {
+			v.logger.Warn("Replay stopped", "error", err)
+			return
+		}
+		select {
+		case <-v.done:
+			return
+		default:
+		}
+		if !*loop {
+			return
+		}
+		if !v.clear(world) {
+			return
+		}
+	}
+}
+
+// The players and spores a pass has left in the viewer's world
+type leftovers struct {
+	players map[uint64]bool
+	spores  map[uint64]bool
+}
+
+func (l *leftovers) track(packet *packets.Packet) {
+	switch msg := packet.Msg.(type) {
+	case *packets.Packet_Player:
+		l.players[msg.Player.Id] = true
+	case *packets.Packet_Id:
+		delete(l.players, msg.Id.Id)
+	case *packets.Packet_Spore:
+		l.spores[msg.Spore.Id] = true
+	case *packets.Packet_SporeConsumed:
+		delete(l.spores, msg.SporeConsumed.SporeId)
+	}
+}
+
+// Removes everything the last pass left behind, as if the players had left and
+// the spores had been eaten, so the next pass starts from an empty world.
+// Returns false if the viewer left first.
+func (v *viewer) clear(world *leftovers) bool {
+	var removals []*packets.Packet
+	for id := range world.players {
+		removals = append(removals, &packets.Packet{SenderId: id, Msg: packets.NewId(id)})
+	}
+	for id := range world.spores {
+		removals = append(removals, &packets.Packet{Msg: packets.NewSporeConsumed(id)})
+	}
+	for _, packet := range removals {
+		select {
+		case v.send <- packet:
+		case <-v.done:
+			return false
+		}
+	}
+	return true
+}
+
+// Plays the replay through once, returning what's left in the world at the end
+func (v *viewer) playOnce() (*leftovers, error) {
+	file, err := os.Open(v.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := replay.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	timeline := newTimeline(reader.Header().TickInterval)
+	world := &leftovers{players: make(map[uint64]bool), spores: make(map[uint64]bool)}
+
+	start := time.Now()
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return world, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record.Tuning != nil {
+			timeline.change(record.Tick, record.Tuning.TickInterval)
+			continue
+		}
+
+		due := time.Duration(float64(timeline.when(record.Tick)) / *speed)
+		if wait := time.Until(start.Add(due)); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-v.done:
+				return world, nil
+			}
+		}
+		select {
+		case v.send <- record.Packet:
+			world.track(record.Packet)
+		case <-v.done:
+			return world, nil
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// The /admin HTTP API for managing a live server. Every action goes through
+// the hub, the same as it would from inside the game.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"server/internal/server"
+	"server/internal/server/config"
+	"server/internal/server/states"
+	"server/internal/server/store"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type handler struct {
+	hub *server.Hub
+}
+
+// Serves the admin API under /admin/, for requests that carry the token as a
+// bearer token
+func NewHandler(hub *server.Hub, token string) http.Handler {
+	h := &handler{hub: hub}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/clients", h.listClients)
+	mux.HandleFunc("POST /admin/clients/{id}/kick", h.kickClient)
+	mux.HandleFunc("POST /admin/clients/{id}/ban", h.banClient)
+	mux.HandleFunc("POST /admin/clients/{id}/mute", h.muteClient)
+	mux.HandleFunc("POST /admin/announce", h.announce)
+	mux.HandleFunc("GET /admin/users", h.listUsers)
+	mux.HandleFunc("PATCH /admin/users/{id}", h.editUser)
+	mux.HandleFunc("PUT /admin/users/{id}/ban", h.banUser)
+	mux.HandleFunc("DELETE /admin/users/{id}/ban", h.unbanUser)
+	mux.HandleFunc("GET /admin/tuning", h.getTuning)
+
+	return requireToken(token, mux)
+}
+
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("missing or wrong admin token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type playerInfo struct {
+	Name   string  `json:"name"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"`
+}
+
+type clientInfo struct {
+	Id     uint64      `json:"id"`
+	State  string      `json:"state"`
+	UserId int64       `json:"user_id"`
+	RttMs  int64       `json:"rtt_ms"`
+	Muted  bool        `json:"muted"`
+	Room   uint64      `json:"room,omitempty"`
+	Player *playerInfo `json:"player,omitempty"`
+}
+
+func (h *handler) listClients(w http.ResponseWriter, r *http.Request) {
+	clients := []clientInfo{}
+	h.hub.Clients.ForEach(func(id uint64, client server.ClientInterface) {
+		userId := client.UserId()
+		info := clientInfo{
+			Id:     id,
+			State:  client.StateName(),
+			UserId: userId,
+			RttMs:  client.Rtt().Milliseconds(),
+			Muted:  userId != 0 && h.hub.Muted(userId),
+		}
+		if room := client.Room(); room != nil {
+			info.Room = room.Id
+			if player, exists := room.World.Players.Get(id); exists {
+				player = player.Snapshot()
+				info.Player = &playerInfo{Name: player.Name, X: player.X, Y: player.Y, Radius: player.Radius}
+			}
+		}
+		clients = append(clients, info)
+	})
+	writeJson(w, http.StatusOK, clients)
+}
+
+func (h *handler) kickClient(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	clientId, ok := parseClientRequest(w, r, &body)
+	if !ok {
+		return
+	}
+	writeResult(w, h.hub.Kick(clientId, body.Reason))
+}
+
+func (h *handler) banClient(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	clientId, ok := parseClientRequest(w, r, &body)
+	if !ok {
+		return
+	}
+	client, exists := h.hub.Clients.Get(clientId)
+	if !exists {
+		writeResult(w, fmt.Errorf("%w: %d", server.ErrNoSuchClient, clientId))
+		return
+	}
+	if client.UserId() == 0 {
+		writeResult(w, fmt.Errorf("%w: %d", server.ErrNotLoggedIn, clientId))
+		return
+	}
+	writeResult(w, h.hub.Ban(r.Context(), client.UserId(), body.Reason))
+}
+
+func (h *handler) muteClient(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Duration config.Duration `json:"duration"`
+	}
+	clientId, ok := parseClientRequest(w, r, &body)
+	if !ok {
+		return
+	}
+	writeResult(w, h.hub.Mute(clientId, body.Duration.Duration))
+}
+
+func (h *handler) announce(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if !readJson(w, r, &body) {
+		return
+	}
+	if body.Message == "" {
+		writeError(w, http.StatusBadRequest, errors.New("message must not be empty"))
+		return
+	}
+	h.hub.Announce(body.Message)
+	writeResult(w, nil)
+}
+
+type userInfo struct {
+	Id           int64  `json:"id"`
+	Username     string `json:"username"`
+	BestScore    int64  `json:"best_score"`
+	GamesPlayed  int64  `json:"games_played"`
+	PlayersEaten int64  `json:"players_eaten"`
+	Banned       bool   `json:"banned"`
+	BanReason    string `json:"ban_reason,omitempty"`
+}
+
+func (h *handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.hub.AdminDbTx().Users.ListUsers(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	users := make([]userInfo, len(summaries))
+	for i, summary := range summaries {
+		users[i] = userInfo{
+			Id:           summary.Id,
+			Username:     summary.Username,
+			BestScore:    summary.Stats.BestScore,
+			GamesPlayed:  summary.Stats.GamesPlayed,
+			PlayersEaten: summary.Stats.PlayersEaten,
+			Banned:       summary.Banned,
+			BanReason:    summary.Ban.Reason,
+		}
+	}
+	writeJson(w, http.StatusOK, users)
+}
+
+// Renames the user and/or resets their password
+func (h *handler) editUser(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Username *string `json:"username"`
+		Password *string `json:"password"`
+	}
+	userId, ok := parseUserRequest(w, r, &body)
+	if !ok {
+		return
+	}
+
+	var username, passwordHash string
+	if body.Username != nil {
+		if err := states.ValidateUsername(*body.Username); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid username: %w", err))
+			return
+		}
+		username = strings.ToLower(*body.Username)
+	}
+	if body.Password != nil {
+		hash, err := bcrypt.GenerateFromPassword([]byte(*body.Password), bcrypt.DefaultCost)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		passwordHash = string(hash)
+	}
+
+	// Both changes or neither
+	dbTx := h.hub.AdminDbTx()
+	defer dbTx.Close()
+	err := dbTx.InTx(func(ctx context.Context, tx store.Tx) error {
+		if _, err := tx.GetUserById(ctx, userId); err != nil {
+			return err
+		}
+		if username != "" {
+			if err := tx.UpdateUsername(ctx, userId, username); err != nil {
+				return err
+			}
+		}
+		if passwordHash != "" {
+			return tx.UpdatePasswordHash(ctx, userId, passwordHash)
+		}
+		return nil
+	})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.hub.Logger.Info("Edited user", "edited_user_id", userId)
+	writeResult(w, nil)
+}
+
+func (h *handler) banUser(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	userId, ok := parseUserRequest(w, r, &body)
+	if !ok {
+		return
+	}
+	if _, err := h.hub.AdminDbTx().Users.GetUserById(r.Context(), userId); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeResult(w, h.hub.Ban(r.Context(), userId, body.Reason))
+}
+
+func (h *handler) unbanUser(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid user id"))
+		return
+	}
+	writeResult(w, h.hub.Unban(r.Context(), userId))
+}
+
+func (h *handler) getTuning(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, h.hub.Tuning())
+}
+
+// Reads the client id from the path and the request body into body
+func parseClientRequest(w http.ResponseWriter, r *http.Request, body any) (uint64, bool) {
+	clientId, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid client id"))
+		return 0, false
+	}
+	return clientId, readJson(w, r, body)
+}
+
+// Reads the user id from the path and the request body into body
+func parseUserRequest(w http.ResponseWriter, r *http.Request, body any) (int64, bool) {
+	userId, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid user id"))
+		return 0, false
+	}
+	return userId, readJson(w, r, body)
+}
+
+// Decodes the request body, which may be empty, into body
+func readJson(w http.ResponseWriter, r *http.Request, body any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeResult(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		writeJson(w, http.StatusOK, map[string]string{"status": "ok"})
+	case errors.Is(err, server.ErrNoSuchClient):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, server.ErrNotLoggedIn):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		writeError(w, http.StatusNotFound, errors.New("no such user"))
+	case errors.Is(err, store.ErrUserExists):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJson(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJson(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
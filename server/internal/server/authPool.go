@@ -0,0 +1,48 @@
+package server
+
+import "server/internal/server/metrics"
+
+// Runs logins and registrations off the clients' goroutines. Hashing passwords
+// is deliberately slow, so only a few run at once and the rest wait in a
+// bounded queue, beyond which new requests are turned away.
+type authPool struct {
+	jobs chan func()
+}
+
+func newAuthPool(workers, queueSize int, quit <-chan struct{}) *authPool {
+	p := &authPool{jobs: make(chan func(), queueSize)}
+	for range workers {
+		go p.work(quit)
+	}
+	return p
+}
+
+func (p *authPool) work(quit <-chan struct{}) {
+	for {
+		select {
+		case job := <-p.jobs:
+			metrics.AuthQueueLength.Dec()
+			job()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Queues the job, or returns false straight away if the queue is full
+func (p *authPool) submit(job func()) bool {
+	select {
+	case p.jobs <- job:
+		metrics.AuthQueueLength.Inc()
+		return true
+	default:
+		metrics.AuthRejections.Inc()
+		return false
+	}
+}
+
+// Runs the job on the auth pool, which hashes passwords for everyone. Returns
+// false if too many are already waiting, in which case the job never runs.
+func (h *Hub) SubmitAuth(job func()) bool {
+	return h.auth.submit(job)
+}
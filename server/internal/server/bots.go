@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"server/internal/server/metrics"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// How often the population is checked against the minimum
+const botCheckInterval = time.Second
+
+// Keeps each room at the configured minimum of players by adding bots, and
+// removes them again as humans join. Only the first room gets bots while it has
+// no humans, so the others can empty out and close, and ranked matches never
+// do.
+type botKeeper struct {
+	hub *Hub
+
+	// Makes a client for the room, which the keeper then has the hub register.
+	// Nil until the hub is told how to make bots.
+	newBot func(hub *Hub, room *Room, name string) ClientInterface
+
+	mux      sync.Mutex
+	bots     []managedBot
+	nextName int
+
+	// When Hub.Step last checked the population
+	stepped time.Time
+}
+
+// A bot the keeper added, and the room it was made for
+type managedBot struct {
+	client ClientInterface
+	home   *Room
+}
+
+// Has the hub keep rooms populated with bots made by newBot, if the config asks
+// for any. Call it before the hub runs.
+func (h *Hub) UseBots(newBot func(hub *Hub, room *Room, name string) ClientInterface) {
+	h.bots.newBot = newBot
+}
+
+func (k *botKeeper) enabled() bool {
+	return k.newBot != nil && k.hub.Config.Bots.MinPopulation > 0
+}
+
+// Checks the population every botCheckInterval until the hub stops
+func (k *botKeeper) run() {
+	if !k.enabled() {
+		return
+	}
+
+	ticker := k.hub.Clock.NewTicker(botCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+		case <-k.hub.quit:
+			return
+		}
+		if k.hub.running.Load() && !k.hub.Draining() {
+			k.balance()
+		}
+	}
+}
+
+// Checks the population if it's been botCheckInterval since Hub.Step last did,
+// and waits for the bots added to be playing and those removed to be gone, so
+// that the next tick always sees the same world
+func (k *botKeeper) step(ctx context.Context) error {
+	if !k.enabled() || k.hub.Draining() {
+		return nil
+	}
+	now := k.hub.Clock.Now()
+	if !k.stepped.IsZero() && now.Sub(k.stepped) < botCheckInterval {
+		return nil
+	}
+	k.stepped = now
+
+	added, removed := k.balance()
+	for _, bot := range added {
+		// It has entered its first state once it has a state name, and Settle
+		// then waits for that to finish
+		err := waitFor(ctx, func() bool { return bot.StateName() != "None" })
+		if err == nil {
+			err = Settle(ctx, bot)
+		}
+		if err != nil {
+			return fmt.Errorf("adding a bot: %w", err)
+		}
+	}
+	for _, bot := range removed {
+		err := waitFor(ctx, func() bool {
+			_, exists := k.hub.Clients.Get(bot.Id())
+			return !exists
+		})
+		if err != nil {
+			return fmt.Errorf("removing bot %d: %w", bot.Id(), err)
+		}
+	}
+	return nil
+}
+
+// Adds and removes bots to bring each room to the minimum population, returning
+// the ones it did
+func (k *botKeeper) balance() (added, removed []ClientInterface) {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	h := k.hub
+
+	// Forget bots that have gone, e.g. because an admin kicked them or their
+	// room closed. Bots that haven't been registered yet still have id 0.
+	isBot := make(map[uint64]bool)
+	k.bots = slices.DeleteFunc(k.bots, func(bot managedBot) bool {
+		id := bot.client.Id()
+		if _, exists := h.Clients.Get(id); id != 0 && !exists {
+			return true
+		}
+		isBot[id] = true
+		return false
+	})
+
+	var kept []managedBot
+	for _, room := range h.Rooms.List() {
+		// The room may have been full, so a bot can end up playing somewhere
+		// other than where it was made for
+		var roomBots []managedBot
+		for _, bot := range k.bots {
+			if where := bot.client.Room(); where == room || where == nil && bot.home == room {
+				roomBots = append(roomBots, bot)
+			}
+		}
+
+		humans := 0
+		for _, id := range room.World.Players.Ids() {
+			if !isBot[id] {
+				humans++
+			}
+		}
+		wanted := max(0, min(h.Config.Bots.MinPopulation, room.MaxPlayers)-humans)
+		if humans == 0 && !room.permanent || room.Ranked() {
+			wanted = 0
+		}
+
+		if len(roomBots) < wanted {
+			room.logger.Info("Adding bots", "count", wanted-len(roomBots), "humans", humans)
+		}
+		for len(roomBots) < wanted {
+			bot := managedBot{client: k.newBot(h, room, fmt.Sprintf("Bot %d", k.nextName+1)), home: room}
+			k.nextName++
+			h.Join(bot.client)
+			roomBots = append(roomBots, bot)
+			added = append(added, bot.client)
+		}
+
+		if len(roomBots) > wanted {
+			room.logger.Info("Removing bots", "count", len(roomBots)-wanted, "humans", humans)
+		}
+		for len(roomBots) > wanted {
+			bot := roomBots[len(roomBots)-1]
+			bot.client.CloseWithCode(websocket.CloseNormalClosure, "Making room for humans")
+			roomBots = roomBots[:len(roomBots)-1]
+			removed = append(removed, bot.client)
+		}
+		kept = append(kept, roomBots...)
+	}
+
+	// Bots in rooms that have closed have already gone, but one that hasn't
+	// joined a room yet will find another
+	for _, bot := range k.bots {
+		if bot.client.Room() == nil && !slices.Contains(kept, bot) {
+			kept = append(kept, bot)
+		}
+	}
+	k.bots = kept
+	metrics.Bots.Set(float64(len(k.bots)))
+	return added, removed
+}
+
+// Polls until the condition holds or the context is done
+func waitFor(ctx context.Context, condition func() bool) error {
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for !condition() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package clients
+
+import (
+	"log/slog"
+	"server/internal/server"
+	"server/internal/server/config"
+	"server/internal/server/metrics"
+	"server/pkg/packets"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// What every kind of client shares: its id, state, room, logger and the inbox
+// its state runs from. Each client embeds one and adds how packets get to and
+// from the other end.
+type clientBase struct {
+	// The client embedding this, which is what the states and the hub see
+	self server.StateClient
+
+	id        atomic.Uint64
+	hub       *server.Hub
+	inbox     *inbox
+	done      chan struct{}
+	closeOnce sync.Once
+	dbTx      *server.DbTx
+	state     server.ClientStateHandler
+	room      atomic.Pointer[server.Room]
+
+	// What the other end may send. Always on the wall clock, as stepping or
+	// stalling the game doesn't change how fast anyone can send.
+	limiter *inboundLimiter
+
+	// Called once as the client closes, before done is, to tell the other end
+	onClose func(code int, reason string)
+
+	// The logger carries the user id and state name, so it's rebuilt whenever
+	// they change. logFields are the client's own, like where it connected from.
+	logMux    sync.Mutex
+	logger    *slog.Logger
+	logFields []any
+	userId    int64
+	stateName string
+}
+
+// Sets up the base for the client embedding it
+func (c *clientBase) init(self server.StateClient, hub *server.Hub, logFields ...any) {
+	c.self = self
+	c.hub = hub
+	c.inbox = newInbox(hub.Config.Network.InboxLimit)
+	c.done = make(chan struct{})
+	c.stateName = "None"
+	c.logFields = logFields
+	c.dbTx = hub.NewDbTx(c.Logger)
+	c.limiter = newInboundLimiter(hub.Config.Network.RateLimits, time.Now)
+	c.updateLogger(func() {})
+}
+
+func (c *clientBase) Id() uint64 {
+	return c.id.Load()
+}
+
+// Queues the message for the state, which handles it on the client's own goroutine
+func (c *clientBase) ProcessMessage(senderId uint64, msg packets.Msg) {
+	c.Deliver(func() {
+		if c.state != nil {
+			c.state.HandleMessage(senderId, msg)
+		}
+	})
+}
+
+// Checks a message from the other end against the rate limits. Returns false if
+// it should be dropped, and an error once the client has broken the limits often
+// enough to be disconnected.
+func (c *clientBase) admit(msg packets.Msg) (bool, error) {
+	allowed, err := c.limiter.allow(msg)
+	if err != nil || allowed {
+		return allowed, err
+	}
+	c.Logger().Info("Rate limited, dropping packet", "type", packets.MsgName(msg))
+	if kindOf(msg) == kindAuth {
+		c.SocketSend(packets.NewDenyResponse("Too many attempts, please wait a moment"))
+	}
+	return false, nil
+}
+
+// Takes the id the hub gave the client, and enters the first state
+func (c *clientBase) start(id uint64, state server.ClientStateHandler) {
+	c.updateLogger(func() { c.id.Store(id) })
+	c.Deliver(func() {
+		c.SetState(state)
+	})
+}
+
+func (c *clientBase) SetState(state server.ClientStateHandler) {
+	prevStateName := "None"
+	if c.state != nil {
+		prevStateName = c.state.Name()
+		c.state.OnExit()
+	}
+
+	newStateName := "None"
+	if state != nil {
+		newStateName = state.Name()
+	}
+
+	c.Logger().Info("Switching state", "from", prevStateName, "to", newStateName)
+	c.updateLogger(func() { c.stateName = newStateName })
+	if prevStateName != "None" {
+		metrics.ClientsByState.With(prevStateName).Dec()
+	}
+	if newStateName != "None" {
+		metrics.ClientsByState.With(newStateName).Inc()
+	}
+
+	c.state = state
+
+	if c.state != nil {
+		c.state.SetClient(c.self)
+		c.state.OnEnter()
+	}
+}
+
+func (c *clientBase) DbTx() *server.DbTx {
+	return c.dbTx
+}
+
+func (c *clientBase) Room() *server.Room {
+	return c.room.Load()
+}
+
+func (c *clientBase) SetRoom(room *server.Room) {
+	c.room.Store(room)
+}
+
+func (c *clientBase) Rooms() *server.Rooms {
+	return c.hub.Rooms
+}
+
+func (c *clientBase) Matchmaker() *server.Matchmaker {
+	return c.hub.Matchmaker
+}
+
+func (c *clientBase) SharedGameObjects() *server.SharedGameObjects {
+	if room := c.Room(); room != nil {
+		return room.World
+	}
+	return nil
+}
+
+func (c *clientBase) Config() *config.Config {
+	return c.hub.Config
+}
+
+func (c *clientBase) Tuning() *config.GameConfig {
+	if room := c.Room(); room != nil {
+		return room.Tuning()
+	}
+	return c.hub.Tuning()
+}
+
+// Clients without a network are right next to the world
+func (c *clientBase) Rtt() time.Duration {
+	return 0
+}
+
+func (c *clientBase) Logger() *slog.Logger {
+	c.logMux.Lock()
+	defer c.logMux.Unlock()
+
+	return c.logger
+}
+
+// Applies a change to the logged fields and rebuilds the logger from them
+func (c *clientBase) updateLogger(change func()) {
+	c.logMux.Lock()
+	defer c.logMux.Unlock()
+
+	change()
+	c.logger = slog.Default().With("client_id", c.id.Load()).With(c.logFields...).With(
+		"user_id", c.userId,
+		"state", c.stateName,
+	)
+}
+
+func (c *clientBase) SetUserId(userId int64) {
+	c.updateLogger(func() { c.userId = userId })
+}
+
+func (c *clientBase) UserId() int64 {
+	c.logMux.Lock()
+	defer c.logMux.Unlock()
+
+	return c.userId
+}
+
+func (c *clientBase) StateName() string {
+	c.logMux.Lock()
+	defer c.logMux.Unlock()
+
+	return c.stateName
+}
+
+func (c *clientBase) Muted() bool {
+	userId := c.UserId()
+	return userId != 0 && c.hub.Muted(userId)
+}
+
+func (c *clientBase) SubmitAuth(job func()) bool {
+	return c.hub.SubmitAuth(job)
+}
+
+func (c *clientBase) Deliver(fn func()) {
+	if err := c.inbox.push(fn); err != nil {
+		c.Logger().Warn("Disconnecting client that can't keep up with its inbox", "limit", c.hub.Config.Network.InboxLimit)
+		metrics.SlowConsumerDisconnects.Inc()
+		go c.CloseWithCode(websocket.CloseTryAgainLater, "Too slow to keep up")
+	}
+}
+
+func (c *clientBase) Tick(delta float64) {
+	c.Deliver(func() {
+		if state, ok := c.state.(server.TickHandler); ok {
+			state.OnTick(delta)
+		}
+	})
+}
+
+func (c *clientBase) SocketSend(msg packets.Msg) {
+	c.self.SocketSendAs(c.Id(), msg)
+}
+
+func (c *clientBase) PassToPeer(msg packets.Msg, peerId uint64) {
+	if peer, exists := c.hub.Clients.Get(peerId); exists {
+		peer.ProcessMessage(c.Id(), msg)
+		return
+	}
+	c.Logger().Debug("Peer not found", "peer_id", peerId)
+}
+
+// Sends the message to everyone else in the client's room
+func (c *clientBase) Broadcast(msg packets.Msg) {
+	if room := c.Room(); room != nil {
+		room.Broadcast(&packets.Packet{SenderId: c.Id(), Msg: msg})
+	}
+}
+
+// Runs everything in the inbox until the client has closed and its state has
+// exited
+func (c *clientBase) runInbox() {
+	for !c.inbox.finished() {
+		<-c.inbox.ready
+		for fn, ok := c.inbox.pop(); ok; fn, ok = c.inbox.pop() {
+			fn()
+		}
+	}
+}
+
+func (c *clientBase) Close(reason string) {
+	c.CloseWithCode(websocket.CloseNormalClosure, reason)
+}
+
+func (c *clientBase) CloseWithCode(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.Logger().Info("Client disconnected", "reason", reason)
+
+		// The state has to exit on the client's own goroutine, after whatever
+		// it's doing now
+		c.inbox.close(func() {
+			c.SetState(nil) // Leaving Ingame saves stats, so only then cancel the rest
+			c.dbTx.Close()
+			c.hub.UnregisterChan <- c.self
+		})
+		if c.onClose != nil {
+			c.onClose(code, reason)
+		}
+		close(c.done)
+	})
+}
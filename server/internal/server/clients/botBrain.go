@@ -0,0 +1,110 @@
+package clients
+
+import (
+	"math"
+	"math/rand"
+	"server/internal/server"
+	"server/internal/server/config"
+	"server/internal/server/objects"
+	"server/pkg/packets"
+)
+
+// Chance per think of a wandering bot picking a new direction
+const wanderTurnChance = 0.05
+
+// Steers a bot: flee anyone big enough to eat it, otherwise chase anyone small
+// enough to eat, otherwise head for the nearest spore, otherwise wander
+type botBrain struct {
+	rng       *rand.Rand
+	direction float64
+}
+
+func newBotBrain(rng *rand.Rand) *botBrain {
+	return &botBrain{rng: rng, direction: rng.Float64() * 2 * math.Pi}
+}
+
+// Returns the messages the bot's client would send: a direction, plus a claim
+// for anything it's close enough to eat
+func (b *botBrain) decide(selfId uint64, world *server.SharedGameObjects, tuning *config.GameConfig, cfg config.BotsConfig) []packets.Msg {
+	player, exists := world.Players.Get(selfId)
+	if !exists {
+		return nil
+	}
+	me := player.Snapshot()
+	mePos := objects.PositionSample{X: me.X, Y: me.Y, Radius: me.Radius}
+
+	var msgs []packets.Msg
+	var fleeX, fleeY float64
+	var prey, food *objects.PositionSample
+	preyDist, foodDist := math.Inf(1), math.Inf(1)
+
+	// In id order, so the sums come out the same to the last bit every time
+	for _, id := range world.Players.Ids() {
+		other, exists := world.Players.Get(id)
+		if !exists || id == selfId {
+			continue
+		}
+		other = other.Snapshot()
+		pos := objects.PositionSample{X: other.X, Y: other.Y, Radius: other.Radius}
+		dist := math.Hypot(pos.X-me.X, pos.Y-me.Y)
+		if dist > cfg.SightRange+other.Radius {
+			continue
+		}
+
+		switch {
+		case other.Radius >= me.Radius*tuning.EatRatio:
+			// Run away harder the closer it is
+			weight := 1 / math.Max(dist, 1)
+			fleeX += (me.X - pos.X) * weight
+			fleeY += (me.Y - pos.Y) * weight
+		case me.Radius >= other.Radius*tuning.EatRatio:
+			if objects.CanConsume(mePos, pos, tuning.EatRatio) {
+				msgs = append(msgs, packets.NewPlayerConsumed(id))
+			}
+			if dist < preyDist {
+				preyDist, prey = dist, &pos
+			}
+		}
+	}
+
+	for _, id := range world.Spores.Ids() {
+		spore, exists := world.Spores.Get(id)
+		if !exists {
+			continue
+		}
+		pos := objects.PositionSample{X: spore.X, Y: spore.Y, Radius: spore.Radius}
+		if objects.Overlaps(mePos, pos) {
+			msgs = append(msgs, packets.NewSporeConsumed(id))
+			continue
+		}
+		if dist := math.Hypot(pos.X-me.X, pos.Y-me.Y); dist < foodDist && dist <= cfg.SightRange {
+			foodDist, food = dist, &pos
+		}
+	}
+
+	switch {
+	case fleeX != 0 || fleeY != 0:
+		b.direction = math.Atan2(fleeY, fleeX)
+	case prey != nil:
+		b.direction = math.Atan2(prey.Y-me.Y, prey.X-me.X)
+	case food != nil:
+		b.direction = math.Atan2(food.Y-me.Y, food.X-me.X)
+	default:
+		b.wander(me, tuning.MapSize)
+	}
+
+	return append(msgs, packets.NewPlayerDirection(b.direction, 0, 0))
+}
+
+// Keeps going the same way for a while, turning back towards the middle when
+// up against the edge of the map
+func (b *botBrain) wander(me *objects.Player, mapSize float64) {
+	margin := me.Radius
+	if me.X <= margin || me.Y <= margin || me.X >= mapSize-margin || me.Y >= mapSize-margin {
+		b.direction = math.Atan2(mapSize/2-me.Y, mapSize/2-me.X) + (b.rng.Float64()-0.5)*math.Pi/2
+		return
+	}
+	if b.rng.Float64() < wanderTurnChance {
+		b.direction = b.rng.Float64() * 2 * math.Pi
+	}
+}
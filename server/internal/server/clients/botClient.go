@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"server/internal/server"
+	"server/internal/server/states"
+	"server/pkg/packets"
+	"time"
+)
+
+// A player that lives inside the server. It has no socket: what it's sent is
+// dropped, and as it ticks it hands its inputs straight to the Ingame state,
+// which handles them just like a human's.
+type BotClient struct {
+	clientBase
+	name  string
+	home  *server.Room
+	brain *botBrain
+
+	// When the bot next decides where to go, by the simulation's clock
+	nextThink time.Time
+}
+
+func NewBotClient(hub *server.Hub, room *server.Room, name string) server.ClientInterface {
+	c := &BotClient{
+		name: name,
+		home: room,
+	}
+	c.init(c, hub, "bot", name)
+	return c
+}
+
+// Bots skip logging in and go straight into the room they were made for. Their
+// choices come from the client's own stream of the hub's randomness, so a
+// seeded game plays out the same every time.
+func (c *BotClient) Initialize(id uint64) {
+	c.brain = newBotBrain(c.hub.Random.Stream(id))
+	c.start(id, states.NewIngame(c.home, 0, c.name))
+}
+
+// Bots never log in
+func (c *BotClient) SetUserId(userId int64) {}
+
+// Bots have no socket, so what they're sent goes nowhere
+func (c *BotClient) SocketSendAs(senderId uint64, msg packets.Msg) {}
+
+func (c *BotClient) ReadPump() {
+	c.runInbox()
+}
+
+// Ticks the state, then thinks if it's time to
+func (c *BotClient) Tick(delta float64) {
+	c.Deliver(func() {
+		if state, ok := c.state.(server.TickHandler); ok {
+			state.OnTick(delta)
+		}
+		c.think()
+	})
+}
+
+// There's nothing to write, so this just waits for the bot to close
+func (c *BotClient) WritePump() {
+	<-c.done
+}
+
+// Decides where to go every think interval, acting like a client by handing
+// the state inputs and claims to have eaten things. Runs on the bot's own
+// goroutine as part of a tick, so it happens at the same point of the
+// simulation however busy the server is.
+func (c *BotClient) think() {
+	world := c.SharedGameObjects()
+	if world == nil || c.state == nil {
+		return
+	}
+	now := world.Clock.Now()
+	if now.Before(c.nextThink) {
+		return
+	}
+	c.nextThink = now.Add(c.hub.Config.Bots.ThinkInterval.Duration)
+
+	if _, exists := world.Players.Get(c.Id()); !exists {
+		return
+	}
+	for _, msg := range c.brain.decide(c.Id(), world, c.Tuning(), c.hub.Config.Bots) {
+		c.state.HandleMessage(c.Id(), msg)
+	}
+}
@@ -0,0 +1,84 @@
+package clients
+
+import (
+	"errors"
+	"sync"
+)
+
+var errInboxFull = errors.New("client is not keeping up with its inbox")
+
+// Everything for a client's state to do: messages from its own socket, other
+// clients and the hub, as well as results and ticks delivered from elsewhere.
+// The read pump runs them one at a time, so the state never sees concurrent
+// calls.
+type inbox struct {
+	limit  int
+	mux    sync.Mutex
+	items  []func()
+	closed bool
+
+	// Receives a value (without blocking) whenever something is pushed
+	ready chan struct{}
+}
+
+func newInbox(limit int) *inbox {
+	return &inbox{
+		limit: limit,
+		ready: make(chan struct{}, 1),
+	}
+}
+
+// Queues fn, or drops it if the inbox has been closed. Returns an error if
+// there are already too many items waiting.
+func (q *inbox) push(fn func()) error {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	if len(q.items) >= q.limit {
+		return errInboxFull
+	}
+	q.items = append(q.items, fn)
+	q.signal()
+	return nil
+}
+
+// Queues last, after which nothing else is accepted
+func (q *inbox) close(last func()) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	q.items = append(q.items, last)
+	q.closed = true
+	q.signal()
+}
+
+func (q *inbox) pop() (func(), bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	fn := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+	return fn, true
+}
+
+// Whether the inbox has been closed and everything in it has been run
+func (q *inbox) finished() bool {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	return q.closed && len(q.items) == 0
+}
+
+func (q *inbox) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,121 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/internal/server"
+	"server/internal/server/states"
+	"server/pkg/packets"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+)
+
+var ErrClientClosed = errors.New("client closed")
+
+// A client connected to the hub without a network, for tests. Whoever holds it
+// plays the part of the remote end with Send and Receive, and gets the same
+// treatment from the hub and the states as a websocket client would.
+type MemoryClient struct {
+	clientBase
+	sendQueue *sendQueue
+
+	// How the hub closed the client, set before done is closed
+	closeCode   int
+	closeReason string
+}
+
+func NewMemoryClient(hub *server.Hub) *MemoryClient {
+	c := &MemoryClient{sendQueue: newSendQueue(hub.Config.Network)}
+	c.init(c, hub, "transport", "memory")
+	c.onClose = func(code int, reason string) {
+		c.closeCode = code
+		c.closeReason = reason
+	}
+	return c
+}
+
+// Passes the message to the state as if the remote end had sent it, unless the
+// rate limits drop it or disconnect the client
+func (c *MemoryClient) Send(msg packets.Msg) error {
+	select {
+	case <-c.done:
+		return ErrClientClosed
+	default:
+	}
+
+	// Go through the wire format, so the message is copied and has to encode
+	data, err := proto.Marshal(&packets.Packet{Msg: msg})
+	if err != nil {
+		return err
+	}
+	packet := &packets.Packet{}
+	if err := proto.Unmarshal(data, packet); err != nil {
+		return err
+	}
+
+	// Held to the same limits as a websocket client, so a test can break them
+	allowed, err := c.admit(packet.Msg)
+	if err != nil {
+		c.Logger().Warn("Disconnecting misbehaving client", "error", err)
+		c.CloseWithCode(websocket.ClosePolicyViolation, err.Error())
+		return nil
+	}
+	if allowed {
+		c.ProcessMessage(c.Id(), packet.Msg)
+	}
+	return nil
+}
+
+// Waits for the next packet the remote end would have been sent. Once the
+// client has closed and everything sent before that has been received, returns
+// an error wrapping ErrClientClosed.
+func (c *MemoryClient) Receive(ctx context.Context) (*packets.Packet, error) {
+	for {
+		if packet, ok := c.sendQueue.pop(); ok {
+			return proto.Clone(packet).(*packets.Packet), nil
+		}
+
+		select {
+		case <-c.sendQueue.ready:
+		case <-c.done:
+			if packet, ok := c.sendQueue.pop(); ok {
+				return proto.Clone(packet).(*packets.Packet), nil
+			}
+			return nil, fmt.Errorf("%w with code %d: %s", ErrClientClosed, c.closeCode, c.closeReason)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Closed once the client has been closed, by either end
+func (c *MemoryClient) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *MemoryClient) Initialize(id uint64) {
+	c.start(id, &states.Connected{})
+}
+
+func (c *MemoryClient) SocketSendAs(senderId uint64, msg packets.Msg) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	if err := c.sendQueue.push(&packets.Packet{SenderId: senderId, Msg: msg}); err != nil {
+		go c.CloseWithCode(websocket.CloseTryAgainLater, "Too slow to keep up")
+	}
+}
+
+func (c *MemoryClient) ReadPump() {
+	c.runInbox()
+}
+
+// Packets stay queued until Receive takes them, so there's nothing to do here
+func (c *MemoryClient) WritePump() {
+	<-c.done
+}
@@ -0,0 +1,72 @@
+package clients_test
+
+import (
+	"context"
+	"errors"
+	"server/internal/server/clients"
+	"server/internal/server/config"
+	"server/internal/server/harness"
+	"server/pkg/packets"
+	"strings"
+	"testing"
+	"time"
+)
+
+func isClosed(conn *harness.Conn) bool {
+	select {
+	case <-conn.Client.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// In-memory clients are held to the same limits as the ones on a socket
+func TestMemoryClientDisconnectAtMaxStrikes(t *testing.T) {
+	const maxStrikes = 5
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cfg := harness.Config()
+	cfg.Network.RateLimits.Other = config.RateLimit{Rate: 0.001, Burst: 1}
+	cfg.Network.RateLimits.MaxStrikes = maxStrikes
+	cfg.Network.RateLimits.StrikeDecay.Duration = 0
+	h, err := harness.StartManual(ctx, cfg, time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("starting hub: %v", err)
+	}
+	defer h.Stop(ctx)
+	conn, err := h.Connect(ctx)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+
+	// The burst, then a strike for each message after it
+	for i := range maxStrikes {
+		if err := conn.Send(packets.NewPing(0)); err != nil {
+			t.Fatalf("sending: %v", err)
+		}
+		if isClosed(conn) {
+			t.Fatalf("disconnected after %d strikes, want %d", i, maxStrikes)
+		}
+	}
+	if err := conn.Send(packets.NewPing(0)); err != nil {
+		t.Fatalf("sending the last strike: %v", err)
+	}
+
+	for {
+		_, err := conn.Receive(ctx)
+		if errors.Is(err, clients.ErrClientClosed) {
+			if !strings.Contains(err.Error(), "too many protocol violations") {
+				t.Errorf("closed with %q, want it to be for too many protocol violations", err)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("waiting to be disconnected: %v", err)
+		}
+	}
+	if err := conn.Send(packets.NewPing(0)); !errors.Is(err, clients.ErrClientClosed) {
+		t.Errorf("sending after the last strike got %v, want %v", err, clients.ErrClientClosed)
+	}
+}
@@ -0,0 +1,107 @@
+package clients
+
+import (
+	"errors"
+	"math"
+	"server/internal/server/config"
+	"server/pkg/packets"
+	"time"
+)
+
+var errTooManyStrikes = errors.New("too many protocol violations")
+
+type tokenBucket struct {
+	limit  config.RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit config.RateLimit, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type messageKind int
+
+const (
+	kindDirection messageKind = iota
+	kindChat
+	kindAuth
+	kindOther
+)
+
+func kindOf(msg packets.Msg) messageKind {
+	switch msg.(type) {
+	case *packets.Packet_PlayerDirection:
+		return kindDirection
+	case *packets.Packet_Chat:
+		return kindChat
+	case *packets.Packet_LoginRequest, *packets.Packet_RegisterRequest:
+		return kindAuth
+	}
+	return kindOther
+}
+
+// Keeps track of what a single client is allowed to send. Not thread-safe,
+// it's only used from the client's read pump.
+type inboundLimiter struct {
+	limits  config.RateLimitsConfig
+	buckets map[messageKind]*tokenBucket
+	strikes float64
+	last    time.Time
+	now     func() time.Time
+}
+
+func newInboundLimiter(limits config.RateLimitsConfig, now func() time.Time) *inboundLimiter {
+	t := now()
+	return &inboundLimiter{
+		limits: limits,
+		buckets: map[messageKind]*tokenBucket{
+			kindDirection: newTokenBucket(limits.Direction, t),
+			kindChat:      newTokenBucket(limits.Chat, t),
+			kindAuth:      newTokenBucket(limits.Auth, t),
+			kindOther:     newTokenBucket(limits.Other, t),
+		},
+		last: t,
+		now:  now,
+	}
+}
+
+// Whether the message should be processed. Returns errTooManyStrikes once the
+// client has misbehaved enough to be disconnected.
+func (l *inboundLimiter) allow(msg packets.Msg) (bool, error) {
+	if l.buckets[kindOf(msg)].allow(l.now()) {
+		return true, nil
+	}
+	return false, l.strike()
+}
+
+// Records a violation, returning errTooManyStrikes if it was one too many
+func (l *inboundLimiter) strike() error {
+	now := l.now()
+	if l.limits.StrikeDecay.Duration > 0 {
+		forgiven := float64(now.Sub(l.last)) / float64(l.limits.StrikeDecay.Duration)
+		l.strikes = math.Max(0, l.strikes-forgiven)
+	}
+	l.last = now
+	l.strikes++
+
+	if l.strikes >= float64(l.limits.MaxStrikes) {
+		return errTooManyStrikes
+	}
+	return nil
+}
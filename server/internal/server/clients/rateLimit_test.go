@@ -0,0 +1,112 @@
+package clients
+
+import (
+	"errors"
+	"server/internal/server/config"
+	"server/pkg/packets"
+	"testing"
+	"time"
+)
+
+// A clock that only moves when it's told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+var testLimits = config.RateLimitsConfig{
+	MaxMessageSize: 64,
+	Direction:      config.RateLimit{Rate: 10, Burst: 4},
+	Chat:           config.RateLimit{Rate: 1, Burst: 2},
+	Auth:           config.RateLimit{Rate: 0.5, Burst: 1},
+	Other:          config.RateLimit{Rate: 2, Burst: 3},
+	MaxStrikes:     100,
+	StrikeDecay:    config.Duration{Duration: time.Second},
+}
+
+// Checks which of the messages are let through, one after another
+func expectAllowed(t *testing.T, limiter *inboundLimiter, msg packets.Msg, want ...bool) {
+	t.Helper()
+	for i, wanted := range want {
+		allowed, err := limiter.allow(msg)
+		if err != nil {
+			t.Fatalf("message %d: %v", i+1, err)
+		}
+		if allowed != wanted {
+			t.Fatalf("message %d allowed %v, want %v", i+1, allowed, wanted)
+		}
+	}
+}
+
+// The first n answers are true and the last is false
+func burst(n int) []bool {
+	want := make([]bool, n+1)
+	for i := range n {
+		want[i] = true
+	}
+	return want
+}
+
+func TestBuckets(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   packets.Msg
+		limit config.RateLimit
+	}{
+		{"direction", packets.NewPlayerDirection(0, 0, 0), testLimits.Direction},
+		{"chat", packets.NewChat("hello"), testLimits.Chat},
+		{"login", packets.NewLoginRequest("alice", "password"), testLimits.Auth},
+		{"register", packets.NewRegisterRequest("alice", "password"), testLimits.Auth},
+		{"other", packets.NewPing(0), testLimits.Other},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			limiter := newInboundLimiter(testLimits, clock.Now)
+
+			// The burst, then nothing until a token comes back
+			expectAllowed(t, limiter, test.msg, burst(test.limit.Burst)...)
+			clock.Advance(time.Duration(float64(time.Second) / test.limit.Rate))
+			expectAllowed(t, limiter, test.msg, true, false)
+
+			// Waiting longer never saves up more than the burst
+			clock.Advance(time.Hour)
+			expectAllowed(t, limiter, test.msg, burst(test.limit.Burst)...)
+		})
+	}
+}
+
+func TestBucketsAreSeparate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := newInboundLimiter(testLimits, clock.Now)
+
+	expectAllowed(t, limiter, packets.NewChat("hello"), burst(testLimits.Chat.Burst)...)
+	expectAllowed(t, limiter, packets.NewPlayerDirection(0, 0, 0), true)
+	expectAllowed(t, limiter, packets.NewLoginRequest("alice", "password"), true)
+}
+
+func TestStrikes(t *testing.T) {
+	limits := testLimits
+	limits.MaxStrikes = 3
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := newInboundLimiter(limits, clock.Now)
+	chat := packets.NewChat("hello")
+
+	// Dropped messages are strikes, but only the last one is too many
+	expectAllowed(t, limiter, chat, true, true, false, false)
+
+	// One strike is forgiven, so the next one isn't the last
+	clock.Advance(limits.StrikeDecay.Duration)
+	expectAllowed(t, limiter, chat, true, false)
+
+	if _, err := limiter.allow(chat); !errors.Is(err, errTooManyStrikes) {
+		t.Errorf("the last strike got %v, want %v", err, errTooManyStrikes)
+	}
+}
@@ -0,0 +1,124 @@
+package clients
+
+import (
+	"errors"
+	"server/internal/server/config"
+	"server/internal/server/metrics"
+	"server/pkg/packets"
+	"sync"
+	"time"
+)
+
+var errSlowConsumer = errors.New("client is not keeping up with its send queue")
+
+// Packets waiting to be written to a client. Reliable packets (chat, responses,
+// etc.) are kept in order and never dropped. State updates are coalesced per
+// entity, so a slow client gets the newest state of everything instead of a
+// random subset.
+type sendQueue struct {
+	cfg   config.NetworkConfig
+	mux   sync.Mutex
+	items []*packets.Packet
+
+	// Number of packets popped so far, so that items[i] has absolute index popped+i
+	popped uint64
+
+	// Absolute index of the queued update for each entity that can still be
+	// replaced. Reset whenever a reliable packet is queued, so that an update is
+	// never moved in front of a reliable packet that was sent after it.
+	pending map[uint64]uint64
+
+	slowSince time.Time
+
+	// Receives a value (without blocking) whenever something is pushed
+	ready chan struct{}
+}
+
+func newSendQueue(cfg config.NetworkConfig) *sendQueue {
+	return &sendQueue{
+		cfg:     cfg,
+		pending: make(map[uint64]uint64),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+func (q *sendQueue) push(packet *packets.Packet) error {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if entityId, ok := updateEntity(packet.Msg); ok {
+		if idx, exists := q.pending[entityId]; exists {
+			q.items[idx-q.popped] = packet
+			metrics.DroppedSends.With("coalesced").Inc()
+			return nil
+		}
+		q.pending[entityId] = q.popped + uint64(len(q.items))
+	} else if len(q.pending) > 0 {
+		clear(q.pending)
+	}
+	q.items = append(q.items, packet)
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+
+	return q.checkBacklog()
+}
+
+func (q *sendQueue) pop() (*packets.Packet, bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if len(q.items) == 0 {
+		return nil, false
+	}
+
+	packet := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+	if entityId, ok := updateEntity(packet.Msg); ok && q.pending[entityId] == q.popped {
+		delete(q.pending, entityId)
+	}
+	q.popped++
+
+	if len(q.items) <= q.cfg.SendQueueHighWater {
+		q.slowSince = time.Time{}
+	}
+	return packet, true
+}
+
+func (q *sendQueue) checkBacklog() error {
+	if len(q.items) > q.cfg.SendQueueHardLimit {
+		return errSlowConsumer
+	}
+	if len(q.items) <= q.cfg.SendQueueHighWater {
+		q.slowSince = time.Time{}
+		return nil
+	}
+	if q.slowSince.IsZero() {
+		q.slowSince = time.Now()
+		return nil
+	}
+	if time.Since(q.slowSince) > q.cfg.SlowConsumerTimeout.Duration {
+		return errSlowConsumer
+	}
+	return nil
+}
+
+func (q *sendQueue) len() int {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	return len(q.items)
+}
+
+// The entity a message carries the latest state of, if it's a state update
+// that can be replaced by a newer one
+func updateEntity(msg packets.Msg) (uint64, bool) {
+	switch msg := msg.(type) {
+	case *packets.Packet_Player:
+		return msg.Player.Id, true
+	}
+	return 0, false
+}
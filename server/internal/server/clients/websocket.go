@@ -1,24 +1,30 @@
 package clients
 
 import (
-	"log"
+	"errors"
 	"net/http"
 	"server/internal/server"
+	"server/internal/server/config"
+	"server/internal/server/metrics"
 	"server/internal/server/states"
 	"server/pkg/packets"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"google.golang.org/protobuf/proto"
 )
 
+// Weight of each new sample in the smoothed round-trip time (same as TCP's SRTT)
+const rttSmoothing = 0.125
+
 type WebSocketClient struct {
-	id       uint64
-	conn     *websocket.Conn
-	hub      *server.Hub
-	logger   *log.Logger
-	sendChan chan *packets.Packet
-	dbTx     *server.DbTx
-	state    server.ClientStateHandler
+	clientBase
+	conn         *websocket.Conn
+	cfg          config.NetworkConfig
+	sendQueue    *sendQueue
+	rtt          atomic.Int64
+	lastActivity atomic.Int64
 }
 
 func NewWebSocketClient(hub *server.Hub, writer http.ResponseWriter, request *http.Request) (server.ClientInterface, error) {
@@ -32,155 +38,215 @@ func NewWebSocketClient(hub *server.Hub, writer http.ResponseWriter, request *ht
 
 	conn, err := upgrader.Upgrade(writer, request, nil)
 	if err != nil {
-		log.Printf("Failed to upgrade connection: %v", err)
+		hub.Logger.Warn("Failed to upgrade connection", "remote_addr", request.RemoteAddr, "error", err)
 		return nil, err
 	}
 	var c = &WebSocketClient{
-		id:       uint64(hub.Clients.Len()),
-		conn:     conn,
-		hub:      hub,
-		logger:   log.Default(),
-		sendChan: make(chan *packets.Packet, 256),
-		dbTx:     hub.NewDbTx(),
+		conn:      conn,
+		cfg:       hub.Config.Network,
+		sendQueue: newSendQueue(hub.Config.Network),
 	}
+	c.init(c, hub, "remote_addr", request.RemoteAddr)
+	c.id.Store(uint64(hub.Clients.Len()))
+	c.onClose = c.sendClose
+	c.lastActivity.Store(time.Now().UnixNano())
 	return c, nil
 }
 
-// Implement all required methods for the ClientInterface
-func (c *WebSocketClient) Id() uint64 {
-	return c.id
+func (c *WebSocketClient) Initialize(id uint64) {
+	c.start(id, &states.Connected{})
 }
 
-func (c *WebSocketClient) ProcessMessage(senderId uint64, msg packets.Msg) {
-	c.state.HandleMessage(senderId, msg)
+// The smoothed round-trip time measured with application-level pings, or 0 if
+// no pong has been received yet
+func (c *WebSocketClient) Rtt() time.Duration {
+	return time.Duration(c.rtt.Load())
 }
 
-func (c *WebSocketClient) Initialize(id uint64) {
-	c.SetState(&states.Connected{})
-}
+func (c *WebSocketClient) SocketSendAs(senderId uint64, msg packets.Msg) {
+	select {
+	case <-c.done:
+		metrics.DroppedSends.With("closed").Inc()
+		return
+	default:
+	}
 
-func (c *WebSocketClient) SocketSend(msg packets.Msg) {
-	c.SocketSendAs(c.id, msg)
+	err := c.sendQueue.push(&packets.Packet{SenderId: senderId, Msg: msg})
+	if err != nil {
+		c.Logger().Warn("Disconnecting slow consumer", "queued", c.sendQueue.len(), "error", err)
+		metrics.SlowConsumerDisconnects.Inc()
+		go c.CloseWithCode(websocket.CloseTryAgainLater, "Too slow to keep up")
+	}
 }
 
-func (c *WebSocketClient) DbTx() *server.DbTx {
-	return c.dbTx
+// Reads frames on another goroutine, and runs everything in the inbox on this
+// one until the client has closed and its state has exited
+func (c *WebSocketClient) ReadPump() {
+	c.conn.SetReadLimit(c.limiter.limits.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongWait.Duration))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongWait.Duration))
+	})
+	go c.readFrames()
+
+	c.runInbox()
+	c.Logger().Debug("Closing read pump")
 }
 
-func (c *WebSocketClient) SharedGameObjects() *server.SharedGameObjects {
-	return c.hub.SharedGameObject
-}
+// Handles each frame from the socket until reading fails, which it does once
+// the connection is closed
+func (c *WebSocketClient) readFrames() {
+	defer c.Close("Read pump closed")
 
-func (c *WebSocketClient) SetState(state server.ClientStateHandler) {
-	prevStateName := "None"
-	if c.state != nil {
-		prevStateName = c.state.Name()
-		c.state.OnExit()
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if errors.Is(err, websocket.ErrReadLimit) {
+				c.Logger().Warn("Message too big", "limit", c.limiter.limits.MaxMessageSize)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				c.Logger().Warn("Unexpected close", "error", err)
+			}
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongWait.Duration))
+
+		if err := c.handleFrame(data); err != nil {
+			c.Logger().Warn("Disconnecting misbehaving client", "error", err)
+			c.CloseWithCode(websocket.ClosePolicyViolation, err.Error())
+			return
+		}
 	}
+}
 
-	newStateName := "None"
-	if state != nil {
-		newStateName = state.Name()
+// Decodes and processes a single frame from the peer. Returns an error if the
+// client should be disconnected.
+func (c *WebSocketClient) handleFrame(data []byte) error {
+	packet := &packets.Packet{}
+	err := proto.Unmarshal(data, packet)
+	if err != nil {
+		c.Logger().Warn("Failed to decode packet", "error", err)
+		return c.limiter.strike()
 	}
 
-	c.logger.Printf("Switching from state %s to %s", prevStateName, newStateName)
+	metrics.PacketsIn.With(packets.MsgName(packet.Msg)).Inc()
 
-	c.state = state
+	// Clients can only speak for themselves
+	packet.SenderId = c.Id()
 
-	if c.state != nil {
-		c.state.SetClient(c)
-		c.state.OnEnter()
+	if allowed, err := c.admit(packet.Msg); !allowed {
+		return err
 	}
-}
 
-func (c *WebSocketClient) SocketSendAs(senderId uint64, msg packets.Msg) {
-	select {
-	case c.sendChan <- &packets.Packet{SenderId: senderId, Msg: msg}:
-	default:
-		c.logger.Println("Send channel is full, dropping message: ", msg)
+	// Answering our pings counts, so clients sitting in the lobby, the queue or
+	// watching aren't taken for dead
+	c.lastActivity.Store(time.Now().UnixNano())
+	if c.handleHeartbeat(packet.Msg) {
+		return nil
 	}
+	c.ProcessMessage(packet.SenderId, packet.Msg)
+	return nil
 }
 
-func (c *WebSocketClient) PassToPeer(msg packets.Msg, peerId uint64) {
-	if peer, exists := c.hub.Clients.Get(peerId); exists {
-		peer.ProcessMessage(c.id, msg)
-		return
+// Answers pings and records pongs from the peer, returning true if the message
+// was one of those and shouldn't be passed on to the state
+func (c *WebSocketClient) handleHeartbeat(msg packets.Msg) bool {
+	switch msg := msg.(type) {
+	case *packets.Packet_Ping:
+		c.SocketSend(packets.NewPong(msg.Ping.Timestamp))
+		return true
+	case *packets.Packet_Pong:
+		sample := time.Since(time.Unix(0, msg.Pong.Timestamp))
+		if sample < 0 || sample > c.cfg.PongWait.Duration {
+			c.Logger().Debug("Ignoring pong with bogus timestamp", "timestamp", msg.Pong.Timestamp)
+			return true
+		}
+		c.updateRtt(sample)
+		return true
 	}
-	c.logger.Println("Peer not found: ", peerId)
-}
-
-func (c *WebSocketClient) Broadcast(msg packets.Msg) {
-	c.hub.BroadcastChan <- &packets.Packet{SenderId: c.id, Msg: msg}
+	return false
 }
 
-func (c *WebSocketClient) ReadPump() {
-	defer func() {
-		c.logger.Println("Closing read pump")
-		c.Close("Read pump closed")
-	}()
-	for {
-		_, data, err := c.conn.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				c.logger.Printf("error: %v", err)
-			}
-			break
-		}
-		packet := &packets.Packet{}
-		err = proto.Unmarshal(data, packet)
-		if err != nil {
-			c.logger.Printf("error: %v", err)
-			continue
-		}
-		if packet.SenderId == 0 {
-			packet.SenderId = c.id
-		}
-		c.ProcessMessage(packet.SenderId, packet.Msg)
+func (c *WebSocketClient) updateRtt(sample time.Duration) {
+	prev := c.Rtt()
+	if prev == 0 {
+		c.rtt.Store(int64(sample))
+		return
 	}
+	smoothed := float64(prev) + rttSmoothing*(float64(sample)-float64(prev))
+	c.rtt.Store(int64(smoothed))
 }
 
 func (c *WebSocketClient) WritePump() {
+	// Websocket pings have to arrive well within the peer's pong wait
+	pingTicker := time.NewTicker(c.cfg.PongWait.Duration * 9 / 10)
+	rttTicker := time.NewTicker(c.cfg.RttPeriod.Duration)
+	idleTimer := time.NewTimer(c.cfg.IdleTimeout.Duration)
 	defer func() {
-		c.logger.Println("Closing write pump")
+		pingTicker.Stop()
+		rttTicker.Stop()
+		idleTimer.Stop()
+		c.Logger().Debug("Closing write pump")
 		c.Close("Write pump closed")
 	}()
-	for packet := range c.sendChan {
-		writer, err := c.conn.NextWriter(websocket.BinaryMessage)
-		if err != nil {
-			c.logger.Printf("error: %v", err)
+
+	for {
+		select {
+		case <-c.sendQueue.ready:
+			for packet, ok := c.sendQueue.pop(); ok; packet, ok = c.sendQueue.pop() {
+				if err := c.writePacket(packet); err != nil {
+					c.Logger().Debug("Failed to write packet", "error", err)
+					return
+				}
+			}
+		case <-pingTicker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteWait.Duration))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.Logger().Debug("Failed to write ping", "error", err)
+				return
+			}
+		case <-rttTicker.C:
+			c.SocketSend(packets.NewPing(time.Now().UnixNano()))
+		case <-idleTimer.C:
+			idle := time.Since(time.Unix(0, c.lastActivity.Load()))
+			if idle >= c.cfg.IdleTimeout.Duration {
+				c.Close("Idle timeout")
+				return
+			}
+			// Something came in since, so the timeout runs from then
+			idleTimer.Reset(c.cfg.IdleTimeout.Duration - idle)
+		case <-c.done:
 			return
 		}
-		data, err := proto.Marshal(packet)
-		if err != nil {
-			c.logger.Printf("error: %v", err)
-			continue
-		}
-		_, err = writer.Write(data)
-		if err != nil {
-			c.logger.Printf("error: %v", err)
-			continue
-		}
-		writer.Write([]byte{'\n'})
-		err = writer.Close()
-		if err != nil {
-			c.logger.Printf("error: %v", err)
-			continue
-		}
 	}
 }
 
-func (c *WebSocketClient) Close(reason string) {
-	c.logger.Printf("Client %d disconnected: %s", c.id, reason)
+func (c *WebSocketClient) writePacket(packet *packets.Packet) error {
+	data, err := proto.Marshal(packet)
+	if err != nil {
+		c.Logger().Error("Failed to encode packet", "error", err)
+		return nil
+	}
 
-	// Notify other players about this player leaving
-	if c.state != nil && c.state.Name() == "Ingame" {
-		c.Broadcast(packets.NewId(c.id)) // Use IdMessage to signal player disconnection
+	c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteWait.Duration))
+	writer, err := c.conn.NextWriter(websocket.BinaryMessage)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	if err != nil {
+		return err
+	}
+	writer.Write([]byte{'\n'})
+	if err := writer.Close(); err != nil {
+		return err
 	}
+	metrics.PacketsOut.With(packets.MsgName(packet.Msg)).Inc()
+	return nil
+}
 
-	c.SetState(nil)
-	c.hub.UnregisterChan <- c
+// Tells the peer why it's being closed, and hangs up
+func (c *WebSocketClient) sendClose(code int, reason string) {
+	closeMessage := websocket.FormatCloseMessage(code, reason)
+	c.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(c.cfg.WriteWait.Duration))
 	c.conn.Close()
-	if _, closed := <-c.sendChan; !closed {
-		close(c.sendChan)
-	}
 }
@@ -0,0 +1,93 @@
+package clients_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/server"
+	"server/internal/server/clients"
+	"server/internal/server/config"
+	"server/pkg/packets"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+)
+
+// Serves a hub with the given limits over a test server and connects to it. The
+// hub isn't running, so the client never gets a state, but everything it's sent
+// still goes through the limits.
+func dial(t *testing.T, limits config.RateLimitsConfig) *websocket.Conn {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Server.Storage = "memory"
+	cfg.Network.RateLimits = limits
+	hub := server.NewHub(cfg)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.Serve(clients.NewWebSocketClient, w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg packets.Msg) {
+	t.Helper()
+	data, err := proto.Marshal(&packets.Packet{Msg: msg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+}
+
+// Reads until the server closes the connection, returning why
+func closeError(t *testing.T, conn *websocket.Conn) *websocket.CloseError {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			t.Fatalf("reading got %v, want the server to close the connection", err)
+		}
+		return closeErr
+	}
+}
+
+func TestMessageTooBig(t *testing.T) {
+	limits := config.Default().Network.RateLimits
+	conn := dial(t, limits)
+
+	send(t, conn, packets.NewChat(strings.Repeat("a", int(limits.MaxMessageSize))))
+	if err := closeError(t, conn); err.Code != websocket.CloseMessageTooBig {
+		t.Errorf("closed with %v, want code %d", err, websocket.CloseMessageTooBig)
+	}
+}
+
+func TestDisconnectAfterTooManyStrikes(t *testing.T) {
+	// Nothing is forgiven however quickly the strikes come
+	limits := config.Default().Network.RateLimits
+	limits.StrikeDecay.Duration = 0
+	conn := dial(t, limits)
+
+	// The burst gets through, then every message is a strike until the last
+	for range limits.Chat.Burst + limits.MaxStrikes {
+		send(t, conn, packets.NewChat("hello"))
+	}
+	err := closeError(t, conn)
+	if err.Code != websocket.ClosePolicyViolation || !strings.Contains(err.Text, "too many protocol violations") {
+		t.Errorf("closed with %v, want code %d for too many protocol violations", err, websocket.ClosePolicyViolation)
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// Where the simulation gets the time from. The real clock in production, a
+// ManualClock when a game has to be reproduced exactly.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// A clock that only moves when told to. Its tickers never fire, so a hub on a
+// manual clock doesn't tick by itself and has to be driven with Hub.Step.
+type ManualClock struct {
+	mux sync.Mutex
+	now time.Time
+}
+
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.now
+}
+
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	return manualTicker{}
+}
+
+type manualTicker struct{}
+
+func (manualTicker) C() <-chan time.Time {
+	return nil
+}
+
+func (manualTicker) Reset(d time.Duration) {}
+
+func (manualTicker) Stop() {}
@@ -0,0 +1,486 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix of the environment variables that override config values, e.g.
+// RR_GAME_PLAYER_SPEED overrides Game.PlayerSpeed
+const EnvPrefix = "RR"
+
+type Config struct {
+	Server      ServerConfig      `json:"server"`
+	Network     NetworkConfig     `json:"network"`
+	Game        GameConfig        `json:"game"`
+	Rooms       RoomsConfig       `json:"rooms"`
+	Matchmaking MatchmakingConfig `json:"matchmaking"`
+	Bots        BotsConfig        `json:"bots"`
+}
+
+type ServerConfig struct {
+	Port int `json:"port"`
+
+	// Either sqlite, using the database at DatabasePath, or memory, which
+	// forgets everything on restart
+	Storage      string `json:"storage"`
+	DatabasePath string `json:"database_path"`
+
+	// Longest a single database operation may take
+	DbTimeout Duration `json:"db_timeout"`
+
+	// How many logins and registrations hash passwords at once, and how many
+	// more may wait before new ones are turned away
+	AuthWorkers   int `json:"auth_workers"`
+	AuthQueueSize int `json:"auth_queue_size"`
+
+	// Seed for everything random in the simulation, so a game can be played
+	// back exactly. 0 picks a new one on every start, which is logged.
+	Seed int64 `json:"seed"`
+
+	// Directory to record everything each room broadcasts to, one file per
+	// room, for watching again with cmd/replay. Empty means nothing is recorded.
+	ReplayDir string `json:"replay_dir"`
+
+	// How long players are warned before the server goes down, and how long
+	// we then wait for everything to close
+	ShutdownCountdown Duration `json:"shutdown_countdown"`
+	ShutdownTimeout   Duration `json:"shutdown_timeout"`
+
+	// One of debug, info, warn or error
+	LogLevel string `json:"log_level"`
+
+	// Either text or json
+	LogFormat string `json:"log_format"`
+
+	// Bearer token for the /admin API, which is disabled while it's empty
+	AdminToken string `json:"admin_token"`
+}
+
+type NetworkConfig struct {
+	// Buffer size of the hub's register, unregister and broadcast channels
+	HubChannelSize int `json:"hub_channel_size"`
+
+	WriteWait Duration `json:"write_wait"`
+	PongWait  Duration `json:"pong_wait"`
+	RttPeriod Duration `json:"rtt_period"`
+
+	// A client that sends no packets at all, not even pongs to the pings sent
+	// every rtt_period, for this long is disconnected
+	IdleTimeout Duration `json:"idle_timeout"`
+
+	// A client whose send queue stays above the high water mark for longer than
+	// the timeout, or ever goes above the hard limit, is disconnected
+	SendQueueHighWater  int      `json:"send_queue_high_water"`
+	SendQueueHardLimit  int      `json:"send_queue_hard_limit"`
+	SlowConsumerTimeout Duration `json:"slow_consumer_timeout"`
+
+	// Most messages and other work that may wait for a client's state to get
+	// to them before the client is disconnected
+	InboxLimit int `json:"inbox_limit"`
+
+	RateLimits RateLimitsConfig `json:"rate_limits"`
+}
+
+// Allows Burst messages at once, refilling at Rate messages per second
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type RateLimitsConfig struct {
+	// Frames bigger than this are rejected and the connection is closed
+	MaxMessageSize int64 `json:"max_message_size"`
+
+	Direction RateLimit `json:"direction"`
+	Chat      RateLimit `json:"chat"`
+	Auth      RateLimit `json:"auth"` // Login and register attempts
+	Other     RateLimit `json:"other"`
+
+	// Clients are disconnected once they reach this many strikes. A strike is
+	// given for each rate limited or malformed message, and one is forgiven for
+	// every StrikeDecay without a new one.
+	MaxStrikes  int      `json:"max_strikes"`
+	StrikeDecay Duration `json:"strike_decay"`
+}
+
+// Gameplay tuning. Can be reloaded while the server is running (see
+// Hub.ReloadTuning), taking effect at the next tick.
+type GameConfig struct {
+	TickInterval  Duration `json:"tick_interval"`
+	InitialRadius float64  `json:"initial_radius"`
+
+	// Players of the initial radius move at PlayerSpeed, bigger players slow
+	// down as (initial radius / radius) ^ SpeedFalloff
+	PlayerSpeed  float64 `json:"player_speed"`
+	SpeedFalloff float64 `json:"speed_falloff"`
+
+	// The world is a square of this size with a corner at the origin
+	MapSize float64 `json:"map_size"`
+
+	// Spores kept in the world per 100x100 area of the map
+	SporeDensity float64 `json:"spore_density"`
+	SporeRadius  float64 `json:"spore_radius"`
+
+	// A player has to be this many times bigger than another to eat it
+	EatRatio float64 `json:"eat_ratio"`
+
+	// How far back we rewind the world to check what a client saw, and how far
+	// behind the latest update clients render other players
+	MaxRewind           Duration `json:"max_rewind"`
+	InterpolationDelay  Duration `json:"interpolation_delay"`
+	PositionHistorySize int      `json:"position_history_size"`
+}
+
+// Every room is a world of its own. There's always at least one, and more are
+// opened as the others fill up.
+type RoomsConfig struct {
+	// Players in a room before new ones are sent to another, and the most rooms
+	// open at once. Once they're all full, rooms take more players than this.
+	MaxPlayers int `json:"max_players"`
+	MaxRooms   int `json:"max_rooms"`
+
+	// How long a room other than the first may stay empty before it's closed
+	EmptyTimeout Duration `json:"empty_timeout"`
+}
+
+// Ranked play: logged in players queue up, and are matched with others of a
+// similar rating in a room of their own
+type MatchmakingConfig struct {
+	// Players in a full match, and the fewest a match starts with once someone
+	// has waited max_wait
+	MatchSize    int      `json:"match_size"`
+	MinMatchSize int      `json:"min_match_size"`
+	MaxWait      Duration `json:"max_wait"`
+
+	// How far from their own rating players accept opponents when they join the
+	// queue, how much further for every second they wait, and the furthest ever.
+	// Smaller gaps make for fairer matches and longer waits.
+	InitialRatingGap float64 `json:"initial_rating_gap"`
+	RatingGapGrowth  float64 `json:"rating_gap_growth"`
+	MaxRatingGap     float64 `json:"max_rating_gap"`
+
+	// How long a match lasts, and how often the queue is checked for matches
+	MatchDuration Duration `json:"match_duration"`
+	Interval      Duration `json:"interval"`
+
+	// Where new players' ratings start, how unsure of them we are, and the
+	// least unsure we ever get
+	InitialRating    float64 `json:"initial_rating"`
+	InitialDeviation float64 `json:"initial_deviation"`
+	MinDeviation     float64 `json:"min_deviation"`
+}
+
+// Bots are players that live inside the server, keeping the world busy while
+// there aren't many humans around
+type BotsConfig struct {
+	// Bots are added while there are fewer players than this in the world, and
+	// removed again as humans join. 0 means no bots.
+	MinPopulation int `json:"min_population"`
+
+	// How often each bot decides where to go, and how far away it notices food
+	// and other players
+	ThinkInterval Duration `json:"think_interval"`
+	SightRange    float64  `json:"sight_range"`
+}
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			Storage:           "sqlite",
+			DatabasePath:      "server.db",
+			DbTimeout:         Duration{5 * time.Second},
+			AuthWorkers:       4,
+			AuthQueueSize:     64,
+			ShutdownCountdown: Duration{10 * time.Second},
+			ShutdownTimeout:   Duration{10 * time.Second},
+			LogLevel:          "info",
+			LogFormat:         "text",
+		},
+		Network: NetworkConfig{
+			HubChannelSize:      256,
+			WriteWait:           Duration{10 * time.Second},
+			PongWait:            Duration{60 * time.Second},
+			RttPeriod:           Duration{2 * time.Second},
+			IdleTimeout:         Duration{5 * time.Minute},
+			SendQueueHighWater:  256,
+			SendQueueHardLimit:  4096,
+			SlowConsumerTimeout: Duration{5 * time.Second},
+			InboxLimit:          4096,
+			RateLimits: RateLimitsConfig{
+				MaxMessageSize: 4096,
+				Direction:      RateLimit{Rate: 30, Burst: 60},
+				Chat:           RateLimit{Rate: 1, Burst: 5},
+				Auth:           RateLimit{Rate: 0.2, Burst: 5},
+				Other:          RateLimit{Rate: 20, Burst: 40},
+				MaxStrikes:     10,
+				StrikeDecay:    Duration{10 * time.Second},
+			},
+		},
+		Game: GameConfig{
+			TickInterval:        Duration{50 * time.Millisecond},
+			InitialRadius:       20,
+			PlayerSpeed:         140,
+			SpeedFalloff:        0.3,
+			MapSize:             1000,
+			SporeDensity:        2,
+			SporeRadius:         5,
+			EatRatio:            1.2,
+			MaxRewind:           Duration{250 * time.Millisecond},
+			InterpolationDelay:  Duration{100 * time.Millisecond},
+			PositionHistorySize: 64,
+		},
+		Rooms: RoomsConfig{
+			MaxPlayers:   20,
+			MaxRooms:     16,
+			EmptyTimeout: Duration{30 * time.Second},
+		},
+		Matchmaking: MatchmakingConfig{
+			MatchSize:        4,
+			MinMatchSize:     2,
+			MaxWait:          Duration{60 * time.Second},
+			InitialRatingGap: 100,
+			RatingGapGrowth:  10,
+			MaxRatingGap:     800,
+			MatchDuration:    Duration{3 * time.Minute},
+			Interval:         Duration{time.Second},
+			InitialRating:    1500,
+			InitialDeviation: 350,
+			MinDeviation:     50,
+		},
+		Bots: BotsConfig{
+			MinPopulation: 0,
+			ThinkInterval: Duration{200 * time.Millisecond},
+			SightRange:    300,
+		},
+	}
+}
+
+// Loads the defaults, then the JSON file at path (if path isn't empty), then
+// any overrides from the environment. The result isn't validated, so that
+// flags can still be applied on top of it.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("parsing config %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), EnvPrefix, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port must be between 1 and 65535")
+	check(c.Server.Storage == "sqlite" || c.Server.Storage == "memory", "server.storage must be sqlite or memory")
+	check(c.Server.Storage != "sqlite" || c.Server.DatabasePath != "", "server.database_path must not be empty")
+	check(c.Server.DbTimeout.Duration > 0, "server.db_timeout must be positive")
+	check(c.Server.AuthWorkers > 0, "server.auth_workers must be positive")
+	check(c.Server.AuthQueueSize >= 0, "server.auth_queue_size must not be negative")
+	check(c.Server.ShutdownCountdown.Duration >= 0, "server.shutdown_countdown must not be negative")
+	check(c.Server.ShutdownTimeout.Duration > 0, "server.shutdown_timeout must be positive")
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Server.LogLevel)) == nil, "server.log_level must be debug, info, warn or error")
+	check(c.Server.LogFormat == "text" || c.Server.LogFormat == "json", "server.log_format must be text or json")
+
+	n := c.Network
+	check(n.HubChannelSize > 0, "network.hub_channel_size must be positive")
+	check(n.WriteWait.Duration > 0, "network.write_wait must be positive")
+	check(n.PongWait.Duration > 0, "network.pong_wait must be positive")
+	check(n.RttPeriod.Duration > 0, "network.rtt_period must be positive")
+	check(n.IdleTimeout.Duration > n.RttPeriod.Duration, "network.idle_timeout must be longer than rtt_period")
+	check(n.SendQueueHighWater > 0, "network.send_queue_high_water must be positive")
+	check(n.SendQueueHardLimit >= n.SendQueueHighWater, "network.send_queue_hard_limit must be at least send_queue_high_water")
+	check(n.SlowConsumerTimeout.Duration > 0, "network.slow_consumer_timeout must be positive")
+	check(n.InboxLimit > 0, "network.inbox_limit must be positive")
+
+	r := n.RateLimits
+	check(r.MaxMessageSize > 0, "network.rate_limits.max_message_size must be positive")
+	limits := []struct {
+		name  string
+		limit RateLimit
+	}{{"direction", r.Direction}, {"chat", r.Chat}, {"auth", r.Auth}, {"other", r.Other}}
+	for _, l := range limits {
+		check(l.limit.Rate > 0 && l.limit.Burst > 0, "network.rate_limits.%s must have a positive rate and burst", l.name)
+	}
+	check(r.MaxStrikes > 0, "network.rate_limits.max_strikes must be positive")
+	check(r.StrikeDecay.Duration >= 0, "network.rate_limits.strike_decay must not be negative")
+
+	errs = append(errs, c.Game.Validate())
+
+	check(c.Rooms.MaxPlayers > 0, "rooms.max_players must be positive")
+	check(c.Rooms.MaxRooms > 0, "rooms.max_rooms must be positive")
+	check(c.Rooms.EmptyTimeout.Duration >= 0, "rooms.empty_timeout must not be negative")
+
+	m := c.Matchmaking
+	check(m.MatchSize >= 2, "matchmaking.match_size must be at least 2")
+	check(m.MinMatchSize >= 2 && m.MinMatchSize <= m.MatchSize, "matchmaking.min_match_size must be between 2 and match_size")
+	check(m.MaxWait.Duration >= 0, "matchmaking.max_wait must not be negative")
+	check(m.InitialRatingGap >= 0, "matchmaking.initial_rating_gap must not be negative")
+	check(m.RatingGapGrowth >= 0, "matchmaking.rating_gap_growth must not be negative")
+	check(m.MaxRatingGap >= m.InitialRatingGap, "matchmaking.max_rating_gap must be at least initial_rating_gap")
+	check(m.MatchDuration.Duration > 0, "matchmaking.match_duration must be positive")
+	check(m.Interval.Duration > 0, "matchmaking.interval must be positive")
+	check(m.MinDeviation > 0, "matchmaking.min_deviation must be positive")
+	check(m.InitialDeviation >= m.MinDeviation, "matchmaking.initial_deviation must be at least min_deviation")
+
+	check(c.Bots.MinPopulation >= 0, "bots.min_population must not be negative")
+	check(c.Bots.MinPopulation <= c.Rooms.MaxPlayers, "bots.min_population must not be more than rooms.max_players")
+	check(c.Bots.ThinkInterval.Duration > 0, "bots.think_interval must be positive")
+	check(c.Bots.SightRange > 0, "bots.sight_range must be positive")
+
+	return errors.Join(errs...)
+}
+
+func (g GameConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(g.TickInterval.Duration >= time.Millisecond, "game.tick_interval must be at least 1ms")
+	check(g.InitialRadius > 0, "game.initial_radius must be positive")
+	check(g.PlayerSpeed > 0, "game.player_speed must be positive")
+	check(g.SpeedFalloff >= 0, "game.speed_falloff must not be negative")
+	check(g.MapSize > 0, "game.map_size must be positive")
+	check(g.SporeDensity >= 0, "game.spore_density must not be negative")
+	check(g.SporeRadius > 0, "game.spore_radius must be positive")
+	check(g.EatRatio >= 1, "game.eat_ratio must be at least 1")
+	check(g.MaxRewind.Duration >= 0, "game.max_rewind must not be negative")
+	check(g.InterpolationDelay.Duration >= 0, "game.interpolation_delay must not be negative")
+	check(g.PositionHistorySize > 0, "game.position_history_size must be positive")
+
+	// The history has to reach back at least as far as we're willing to rewind
+	historySpan := time.Duration(g.PositionHistorySize) * g.TickInterval.Duration
+	check(historySpan >= g.MaxRewind.Duration, "game.position_history_size covers %v at this tick interval, less than max_rewind", historySpan)
+
+	return errors.Join(errs...)
+}
+
+// How fast a player of the given radius moves
+func (g GameConfig) SpeedFor(radius float64) float64 {
+	if radius <= g.InitialRadius {
+		return g.PlayerSpeed
+	}
+	return g.PlayerSpeed * math.Pow(g.InitialRadius/radius, g.SpeedFalloff)
+}
+
+// How many spores should be in the world
+func (g GameConfig) SporeCount() int {
+	return int(g.SporeDensity * (g.MapSize / 100) * (g.MapSize / 100))
+}
+
+// Walks the struct, overriding each field that has an environment variable
+// named after its JSON path, e.g. RR_NETWORK_RATE_LIMITS_CHAT_RATE
+func applyEnv(v reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		jsonName, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		name := prefix + "_" + strings.ToUpper(jsonName)
+
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(Duration{}) {
+			if err := applyEnv(field, name, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := setFromString(field, value); err != nil {
+			return fmt.Errorf("environment variable %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setFromString(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(Duration{d}))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// A time.Duration that reads and writes as a string like "50ms" in JSON
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("durations must be strings like \"50ms\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
@@ -0,0 +1,199 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"server/internal/server/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Writes contents to a config file that's removed when the test ends
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, `{
+		"server": {"port": 9000, "log_level": "debug"},
+		"game": {"player_speed": 200, "tick_interval": "40ms"}
+	}`)
+	t.Setenv("RR_SERVER_PORT", "9100")
+	t.Setenv("RR_GAME_TICK_INTERVAL", "20ms")
+	t.Setenv("RR_NETWORK_RATE_LIMITS_CHAT_RATE", "2.5")
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	defaults := config.Default()
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"environment over file", cfg.Server.Port, 9100},
+		{"environment over file for a duration", cfg.Game.TickInterval.Duration, 20 * time.Millisecond},
+		{"file over default", cfg.Server.LogLevel, "debug"},
+		{"file alone", cfg.Game.PlayerSpeed, 200.0},
+		{"environment alone, nested", cfg.Network.RateLimits.Chat.Rate, 2.5},
+		{"default alone", cfg.Game.MapSize, defaults.Game.MapSize},
+		{"default alone, next to the file's", cfg.Server.LogFormat, defaults.Server.LogFormat},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	t.Setenv("RR_GAME_MAP_SIZE", "500")
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	if cfg.Game.MapSize != 500 {
+		t.Errorf("map size is %v, want 500 from the environment", cfg.Game.MapSize)
+	}
+}
+
+func TestLoadBadEnvironment(t *testing.T) {
+	tests := []struct {
+		name, value string
+	}{
+		{"RR_SERVER_PORT", "eighty"},
+		{"RR_SERVER_SEED", "1.5"},
+		{"RR_GAME_PLAYER_SPEED", "fast"},
+		{"RR_GAME_TICK_INTERVAL", "50"},
+		{"RR_NETWORK_RATE_LIMITS_CHAT_BURST", "lots"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(test.name, test.value)
+			_, err := config.Load("")
+			if err == nil || !strings.Contains(err.Error(), test.name) {
+				t.Errorf("got %v, want an error about %s", err, test.name)
+			}
+		})
+	}
+}
+
+func TestLoadBadFile(t *testing.T) {
+	tests := []struct {
+		name, contents string
+	}{
+		{"unknown field", `{"server": {"prot": 9000}}`},
+		{"duration as a number", `{"game": {"tick_interval": 50}}`},
+		{"duration without a unit", `{"game": {"tick_interval": "50"}}`},
+		{"wrong type", `{"server": {"port": "9000"}}`},
+		{"not json", `port = 9000`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := config.Load(writeConfig(t, test.contents)); err == nil {
+				t.Error("loaded without an error")
+			}
+		})
+	}
+
+	if _, err := config.Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("loaded a missing file without an error")
+	}
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := config.Default().Validate(); err != nil {
+		t.Errorf("the defaults don't validate: %v", err)
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	tests := []struct {
+		want   string
+		change func(cfg *config.Config)
+	}{
+		{"server.port", func(cfg *config.Config) { cfg.Server.Port = 0 }},
+		{"server.port", func(cfg *config.Config) { cfg.Server.Port = 65536 }},
+		{"server.storage", func(cfg *config.Config) { cfg.Server.Storage = "postgres" }},
+		{"server.database_path", func(cfg *config.Config) { cfg.Server.DatabasePath = "" }},
+		{"server.db_timeout", func(cfg *config.Config) { cfg.Server.DbTimeout.Duration = 0 }},
+		{"server.auth_workers", func(cfg *config.Config) { cfg.Server.AuthWorkers = 0 }},
+		{"server.auth_queue_size", func(cfg *config.Config) { cfg.Server.AuthQueueSize = -1 }},
+		{"server.shutdown_countdown", func(cfg *config.Config) { cfg.Server.ShutdownCountdown.Duration = -time.Second }},
+		{"server.shutdown_timeout", func(cfg *config.Config) { cfg.Server.ShutdownTimeout.Duration = 0 }},
+		{"server.log_level", func(cfg *config.Config) { cfg.Server.LogLevel = "loud" }},
+		{"server.log_format", func(cfg *config.Config) { cfg.Server.LogFormat = "xml" }},
+
+		{"network.hub_channel_size", func(cfg *config.Config) { cfg.Network.HubChannelSize = 0 }},
+		{"network.write_wait", func(cfg *config.Config) { cfg.Network.WriteWait.Duration = 0 }},
+		{"network.pong_wait", func(cfg *config.Config) { cfg.Network.PongWait.Duration = 0 }},
+		{"network.rtt_period", func(cfg *config.Config) { cfg.Network.RttPeriod.Duration = 0 }},
+		{"network.idle_timeout", func(cfg *config.Config) { cfg.Network.IdleTimeout = cfg.Network.RttPeriod }},
+		{"network.send_queue_high_water", func(cfg *config.Config) { cfg.Network.SendQueueHighWater = 0 }},
+		{"network.send_queue_hard_limit", func(cfg *config.Config) { cfg.Network.SendQueueHardLimit = cfg.Network.SendQueueHighWater - 1 }},
+		{"network.slow_consumer_timeout", func(cfg *config.Config) { cfg.Network.SlowConsumerTimeout.Duration = 0 }},
+		{"network.inbox_limit", func(cfg *config.Config) { cfg.Network.InboxLimit = 0 }},
+		{"network.rate_limits.max_message_size", func(cfg *config.Config) { cfg.Network.RateLimits.MaxMessageSize = 0 }},
+		{"network.rate_limits.direction", func(cfg *config.Config) { cfg.Network.RateLimits.Direction.Rate = 0 }},
+		{"network.rate_limits.chat", func(cfg *config.Config) { cfg.Network.RateLimits.Chat.Burst = 0 }},
+		{"network.rate_limits.auth", func(cfg *config.Config) { cfg.Network.RateLimits.Auth.Rate = -1 }},
+		{"network.rate_limits.other", func(cfg *config.Config) { cfg.Network.RateLimits.Other.Burst = 0 }},
+		{"network.rate_limits.max_strikes", func(cfg *config.Config) { cfg.Network.RateLimits.MaxStrikes = 0 }},
+		{"network.rate_limits.strike_decay", func(cfg *config.Config) { cfg.Network.RateLimits.StrikeDecay.Duration = -time.Second }},
+
+		{"game.tick_interval", func(cfg *config.Config) { cfg.Game.TickInterval.Duration = time.Microsecond }},
+		{"game.initial_radius", func(cfg *config.Config) { cfg.Game.InitialRadius = 0 }},
+		{"game.player_speed", func(cfg *config.Config) { cfg.Game.PlayerSpeed = 0 }},
+		{"game.speed_falloff", func(cfg *config.Config) { cfg.Game.SpeedFalloff = -0.1 }},
+		{"game.map_size", func(cfg *config.Config) { cfg.Game.MapSize = 0 }},
+		{"game.spore_density", func(cfg *config.Config) { cfg.Game.SporeDensity = -1 }},
+		{"game.spore_radius", func(cfg *config.Config) { cfg.Game.SporeRadius = 0 }},
+		{"game.eat_ratio", func(cfg *config.Config) { cfg.Game.EatRatio = 0.9 }},
+		{"game.max_rewind", func(cfg *config.Config) { cfg.Game.MaxRewind.Duration = -time.Second }},
+		{"game.interpolation_delay", func(cfg *config.Config) { cfg.Game.InterpolationDelay.Duration = -time.Second }},
+		{"game.position_history_size must be positive", func(cfg *config.Config) { cfg.Game.PositionHistorySize = 0 }},
+		{"less than max_rewind", func(cfg *config.Config) { cfg.Game.PositionHistorySize = 2 }},
+
+		{"rooms.max_players", func(cfg *config.Config) { cfg.Rooms.MaxPlayers = 0 }},
+		{"rooms.max_rooms", func(cfg *config.Config) { cfg.Rooms.MaxRooms = 0 }},
+		{"rooms.empty_timeout", func(cfg *config.Config) { cfg.Rooms.EmptyTimeout.Duration = -time.Second }},
+
+		{"matchmaking.match_size", func(cfg *config.Config) { cfg.Matchmaking.MatchSize = 1 }},
+		{"matchmaking.min_match_size", func(cfg *config.Config) { cfg.Matchmaking.MinMatchSize = cfg.Matchmaking.MatchSize + 1 }},
+		{"matchmaking.max_wait", func(cfg *config.Config) { cfg.Matchmaking.MaxWait.Duration = -time.Second }},
+		{"matchmaking.initial_rating_gap", func(cfg *config.Config) { cfg.Matchmaking.InitialRatingGap = -1 }},
+		{"matchmaking.rating_gap_growth", func(cfg *config.Config) { cfg.Matchmaking.RatingGapGrowth = -1 }},
+		{"matchmaking.max_rating_gap", func(cfg *config.Config) { cfg.Matchmaking.MaxRatingGap = cfg.Matchmaking.InitialRatingGap - 1 }},
+		{"matchmaking.match_duration", func(cfg *config.Config) { cfg.Matchmaking.MatchDuration.Duration = 0 }},
+		{"matchmaking.interval", func(cfg *config.Config) { cfg.Matchmaking.Interval.Duration = 0 }},
+		{"matchmaking.min_deviation", func(cfg *config.Config) { cfg.Matchmaking.MinDeviation = 0 }},
+		{"matchmaking.initial_deviation", func(cfg *config.Config) { cfg.Matchmaking.InitialDeviation = cfg.Matchmaking.MinDeviation - 1 }},
+
+		{"bots.min_population must not be negative", func(cfg *config.Config) { cfg.Bots.MinPopulation = -1 }},
+		{"bots.min_population must not be more", func(cfg *config.Config) { cfg.Bots.MinPopulation = cfg.Rooms.MaxPlayers + 1 }},
+		{"bots.think_interval", func(cfg *config.Config) { cfg.Bots.ThinkInterval.Duration = 0 }},
+		{"bots.sight_range", func(cfg *config.Config) { cfg.Bots.SightRange = 0 }},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			cfg := config.Default()
+			test.change(cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %v, want an error about %s", err, test.want)
+			}
+
+			// Tuning is reloaded on its own, so it has to catch the same mistakes
+			if strings.HasPrefix(test.want, "game.") || strings.Contains(test.want, "max_rewind") {
+				if err := cfg.Game.Validate(); err == nil || !strings.Contains(err.Error(), test.want) {
+					t.Errorf("validating the tuning alone got %v, want an error about %s", err, test.want)
+				}
+			}
+		})
+	}
+}
@@ -8,4 +8,74 @@ INSERT INTO users (
 ) VALUES (
     ?, ?
 )
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: UpsertPlayerStats :exec
+INSERT INTO player_stats (
+    user_id, best_score, games_played, players_eaten
+) VALUES (
+    ?, ?, 1, ?
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    best_score = MAX(best_score, excluded.best_score),
+    games_played = games_played + 1,
+    players_eaten = players_eaten + excluded.players_eaten;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = ? LIMIT 1;
+
+-- name: ListUsers :many
+SELECT
+    users.id,
+    users.username,
+    COALESCE(player_stats.best_score, 0) AS best_score,
+    COALESCE(player_stats.games_played, 0) AS games_played,
+    COALESCE(player_stats.players_eaten, 0) AS players_eaten,
+    bans.reason AS ban_reason
+FROM users
+LEFT JOIN player_stats ON player_stats.user_id = users.id
+LEFT JOIN bans ON bans.user_id = users.id
+ORDER BY users.id;
+
+-- name: UpdateUsername :exec
+UPDATE users SET username = ?
+WHERE id = ?;
+
+-- name: UpdatePasswordHash :exec
+UPDATE users SET password_hash = ?
+WHERE id = ?;
+
+-- name: GetBan :one
+SELECT * FROM bans
+WHERE user_id = ? LIMIT 1;
+
+-- name: BanUser :exec
+INSERT INTO bans (
+    user_id, reason
+) VALUES (
+    ?, ?
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    reason = excluded.reason,
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: UnbanUser :exec
+DELETE FROM bans
+WHERE user_id = ?;
+
+-- name: GetRating :one
+SELECT * FROM ratings
+WHERE user_id = ? LIMIT 1;
+
+-- name: UpsertRating :exec
+INSERT INTO ratings (
+    user_id, rating, deviation, matches_played
+) VALUES (
+    ?, ?, ?, 1
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    rating = excluded.rating,
+    deviation = excluded.deviation,
+    matches_played = matches_played + 1,
+    updated_at = CURRENT_TIMESTAMP;
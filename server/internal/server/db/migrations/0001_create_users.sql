@@ -1,3 +1,4 @@
+-- Databases created before migrations existed already have this table
 CREATE TABLE IF NOT EXISTS users (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     username TEXT NOT NULL UNIQUE,
@@ -0,0 +1,7 @@
+-- Databases created before migrations existed may already have this table
+CREATE TABLE IF NOT EXISTS player_stats (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    best_score INTEGER NOT NULL DEFAULT 0,
+    games_played INTEGER NOT NULL DEFAULT 0,
+    players_eaten INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,6 @@
+-- Databases created before migrations existed may already have this table
+CREATE TABLE IF NOT EXISTS bans (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,7 @@
+CREATE TABLE ratings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    rating REAL NOT NULL,
+    deviation REAL NOT NULL,
+    matches_played INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -4,6 +4,31 @@
 
 package db
 
+import (
+	"time"
+)
+
+type Ban struct {
+	UserID    int64
+	Reason    string
+	CreatedAt time.Time
+}
+
+type PlayerStat struct {
+	UserID       int64
+	BestScore    int64
+	GamesPlayed  int64
+	PlayersEaten int64
+}
+
+type Rating struct {
+	UserID        int64
+	Rating        float64
+	Deviation     float64
+	MatchesPlayed int64
+	UpdatedAt     time.Time
+}
+
 type User struct {
 	ID           int64
 	Username     string
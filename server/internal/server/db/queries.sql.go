@@ -7,8 +7,30 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const banUser = `-- name: BanUser :exec
+INSERT INTO bans (
+    user_id, reason
+) VALUES (
+    ?, ?
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    reason = excluded.reason,
+    created_at = CURRENT_TIMESTAMP
+`
+
+type BanUserParams struct {
+	UserID int64
+	Reason string
+}
+
+func (q *Queries) BanUser(ctx context.Context, arg BanUserParams) error {
+	_, err := q.db.ExecContext(ctx, banUser, arg.UserID, arg.Reason)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     username, password_hash
@@ -30,6 +52,48 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
+const getBan = `-- name: GetBan :one
+SELECT user_id, reason, created_at FROM bans
+WHERE user_id = ? LIMIT 1
+`
+
+func (q *Queries) GetBan(ctx context.Context, userID int64) (Ban, error) {
+	row := q.db.QueryRowContext(ctx, getBan, userID)
+	var i Ban
+	err := row.Scan(&i.UserID, &i.Reason, &i.CreatedAt)
+	return i, err
+}
+
+const getRating = `-- name: GetRating :one
+SELECT user_id, rating, deviation, matches_played, updated_at FROM ratings
+WHERE user_id = ? LIMIT 1
+`
+
+func (q *Queries) GetRating(ctx context.Context, userID int64) (Rating, error) {
+	row := q.db.QueryRowContext(ctx, getRating, userID)
+	var i Rating
+	err := row.Scan(
+		&i.UserID,
+		&i.Rating,
+		&i.Deviation,
+		&i.MatchesPlayed,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, username, password_hash FROM users
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(&i.ID, &i.Username, &i.PasswordHash)
+	return i, err
+}
+
 const getUserByUsername = `-- name: GetUserByUsername :one
 SELECT id, username, password_hash FROM users
 WHERE username = ? LIMIT 1
@@ -41,3 +105,143 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 	err := row.Scan(&i.ID, &i.Username, &i.PasswordHash)
 	return i, err
 }
+
+const listUsers = `-- name: ListUsers :many
+SELECT
+    users.id,
+    users.username,
+    COALESCE(player_stats.best_score, 0) AS best_score,
+    COALESCE(player_stats.games_played, 0) AS games_played,
+    COALESCE(player_stats.players_eaten, 0) AS players_eaten,
+    bans.reason AS ban_reason
+FROM users
+LEFT JOIN player_stats ON player_stats.user_id = users.id
+LEFT JOIN bans ON bans.user_id = users.id
+ORDER BY users.id
+`
+
+type ListUsersRow struct {
+	ID           int64
+	Username     string
+	BestScore    int64
+	GamesPlayed  int64
+	PlayersEaten int64
+	BanReason    sql.NullString
+}
+
+func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersRow
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.BestScore,
+			&i.GamesPlayed,
+			&i.PlayersEaten,
+			&i.BanReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unbanUser = `-- name: UnbanUser :exec
+DELETE FROM bans
+WHERE user_id = ?
+`
+
+func (q *Queries) UnbanUser(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, unbanUser, userID)
+	return err
+}
+
+const updatePasswordHash = `-- name: UpdatePasswordHash :exec
+UPDATE users SET password_hash = ?
+WHERE id = ?
+`
+
+type UpdatePasswordHashParams struct {
+	PasswordHash string
+	ID           int64
+}
+
+func (q *Queries) UpdatePasswordHash(ctx context.Context, arg UpdatePasswordHashParams) error {
+	_, err := q.db.ExecContext(ctx, updatePasswordHash, arg.PasswordHash, arg.ID)
+	return err
+}
+
+const updateUsername = `-- name: UpdateUsername :exec
+UPDATE users SET username = ?
+WHERE id = ?
+`
+
+type UpdateUsernameParams struct {
+	Username string
+	ID       int64
+}
+
+func (q *Queries) UpdateUsername(ctx context.Context, arg UpdateUsernameParams) error {
+	_, err := q.db.ExecContext(ctx, updateUsername, arg.Username, arg.ID)
+	return err
+}
+
+const upsertPlayerStats = `-- name: UpsertPlayerStats :exec
+INSERT INTO player_stats (
+    user_id, best_score, games_played, players_eaten
+) VALUES (
+    ?, ?, 1, ?
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    best_score = MAX(best_score, excluded.best_score),
+    games_played = games_played + 1,
+    players_eaten = players_eaten + excluded.players_eaten
+`
+
+type UpsertPlayerStatsParams struct {
+	UserID       int64
+	BestScore    int64
+	PlayersEaten int64
+}
+
+func (q *Queries) UpsertPlayerStats(ctx context.Context, arg UpsertPlayerStatsParams) error {
+	_, err := q.db.ExecContext(ctx, upsertPlayerStats, arg.UserID, arg.BestScore, arg.PlayersEaten)
+	return err
+}
+
+const upsertRating = `-- name: UpsertRating :exec
+INSERT INTO ratings (
+    user_id, rating, deviation, matches_played
+) VALUES (
+    ?, ?, ?, 1
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    rating = excluded.rating,
+    deviation = excluded.deviation,
+    matches_played = matches_played + 1,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertRatingParams struct {
+	UserID    int64
+	Rating    float64
+	Deviation float64
+}
+
+func (q *Queries) UpsertRating(ctx context.Context, arg UpsertRatingParams) error {
+	_, err := q.db.ExecContext(ctx, upsertRating, arg.UserID, arg.Rating, arg.Deviation)
+	return err
+}
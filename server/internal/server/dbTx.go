@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"server/internal/server/store"
+	"time"
+)
+
+// A client's access to storage. Each operation gets its own deadline, and
+// they're all cancelled once the DbTx is closed when the client goes away.
+type DbTx struct {
+	Users   store.UserStore
+	Stats   store.StatsStore
+	Ratings store.RatingStore
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	store   store.Store
+	timeout time.Duration
+}
+
+// Queries are logged with whichever logger the function returns at the time
+func (h *Hub) NewDbTx(logger func() *slog.Logger) *DbTx {
+	ctx, cancel := context.WithCancel(store.WithLogger(context.Background(), logger))
+	return &DbTx{
+		Users:   h.store,
+		Stats:   h.store,
+		Ratings: h.store,
+		ctx:     ctx,
+		cancel:  cancel,
+		store:   h.store,
+		timeout: h.Config.Server.DbTimeout.Duration,
+	}
+}
+
+// A context for a single operation, done after the configured timeout or once
+// the DbTx is closed
+func (d *DbTx) OpContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(d.ctx, d.timeout)
+}
+
+// Runs fn in a transaction that's committed if fn returns nil and rolled back
+// otherwise. The whole transaction counts as a single operation.
+func (d *DbTx) InTx(fn func(ctx context.Context, tx store.Tx) error) error {
+	ctx, cancel := d.OpContext()
+	defer cancel()
+
+	return d.store.WithTx(ctx, func(tx store.Tx) error {
+		return fn(ctx, tx)
+	})
+}
+
+// Cancels whatever is still running, and everything after
+func (d *DbTx) Close() {
+	d.cancel()
+}
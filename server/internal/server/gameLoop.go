@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"server/internal/server/config"
+	"server/internal/server/metrics"
+	"server/internal/server/objects"
+	"server/pkg/packets"
+	"time"
+)
+
+// Most spores we spawn in a single tick, so refilling the map doesn't flood clients
+const maxSporesPerTick = 20
+
+// The tuning new rooms start with, i.e. the most recently loaded one
+func (h *Hub) Tuning() *config.GameConfig {
+	return h.tuning.Load()
+}
+
+// Validates the new tuning and schedules it to take effect at the start of
+// every room's next tick. Returns the validation error, if any, leaving the
+// tuning as it is.
+func (h *Hub) ReloadTuning(tuning config.GameConfig) error {
+	if err := tuning.Validate(); err != nil {
+		return err
+	}
+	h.tuning.Store(&tuning)
+	for _, room := range h.Rooms.List() {
+		room.pendingTuning.Store(&tuning)
+	}
+	return nil
+}
+
+// The gameplay tuning in effect in the room for the current tick
+func (r *Room) Tuning() *config.GameConfig {
+	return r.tuning.Load()
+}
+
+// Ticks the room off the clock until it closes or the hub stops
+func (r *Room) runGameLoop() {
+	tickInterval := r.Tuning().TickInterval.Duration
+	ticker := r.World.Clock.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			start := time.Now()
+			tuning := r.tick(nil)
+			metrics.TickDuration.With("world").Observe(time.Since(start).Seconds())
+
+			if tuning.TickInterval.Duration != tickInterval {
+				tickInterval = tuning.TickInterval.Duration
+				ticker.Reset(tickInterval)
+			}
+			r.hub.Rooms.closeIfEmpty(r)
+		case <-r.quit:
+			return
+		case <-r.hub.quit:
+			return
+		}
+	}
+}
+
+// Runs one tick in every room straight away, room by room in id order. In each
+// room, waits for each client to finish its part, and for whatever that set
+// off in other clients (like someone being eaten), before moving on to the
+// next in id order. So given the same seed and the same inputs between
+// steps, the rooms end up exactly the same. Moves a ManualClock on by the tick
+// interval, starts any ranked matches the queue is ready for and adds or
+// removes bots first, and closes rooms that have been empty for long enough
+// after.
+//
+// Meant for a hub on a ManualClock, e.g. in tests and replays. On the real
+// clock the game loops are ticking as well.
+func (h *Hub) Step(ctx context.Context) error {
+	if clock, ok := h.Clock.(*ManualClock); ok {
+		clock.Advance(h.Tuning().TickInterval.Duration)
+	}
+
+	// Matches start as the room's first tick begins
+	for _, entry := range h.Matchmaker.match() {
+		if err := Settle(ctx, entry.Client); err != nil {
+			return err
+		}
+	}
+	if err := h.bots.step(ctx); err != nil {
+		return err
+	}
+
+	for _, room := range h.Rooms.List() {
+		if err := room.step(ctx); err != nil {
+			return err
+		}
+		h.Rooms.closeIfEmpty(room)
+	}
+	return nil
+}
+
+func (r *Room) step(ctx context.Context) error {
+	var err error
+	r.tick(func(client ClientInterface) {
+		// Its turn may have set off something in a client that has already had
+		// its own (like eating it), which has to be done before the next one
+		// looks at the world
+		for range 2 {
+			if err == nil {
+				err = r.settle(ctx)
+			}
+		}
+	})
+	return err
+}
+
+// Waits for every client in the room to handle what's been queued for it
+func (r *Room) settle(ctx context.Context) error {
+	for _, id := range r.Clients.Ids() {
+		if client, exists := r.Clients.Get(id); exists {
+			if err := Settle(ctx, client); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Waits for everything queued for the client so far to have been handled
+func Settle(ctx context.Context, client ClientInterface) error {
+	done := make(chan struct{})
+	client.Deliver(func() { close(done) })
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Applies any reloaded tuning, keeps the spores topped up and ticks every
+// client in the room in id order, calling after (if it isn't nil) once each
+// has been told. Returns the tuning the tick ran with.
+func (r *Room) tick(after func(client ClientInterface)) *config.GameConfig {
+	tuning := r.Tuning()
+	tick := r.ticks.Add(1)
+	if pending := r.pendingTuning.Swap(nil); pending != nil {
+		tuning = pending
+		r.tuning.Store(tuning)
+		r.logger.Info("Applied new game tuning")
+		r.tuningChanged(tick, tuning)
+	}
+	r.maintainSpores(tuning)
+
+	delta := tuning.TickInterval.Seconds()
+	for _, id := range r.Clients.Ids() {
+		client, exists := r.Clients.Get(id)
+		if !exists {
+			continue
+		}
+		client.Tick(delta)
+		if after != nil {
+			after(client)
+		}
+	}
+	r.checkMatch()
+	return tuning
+}
+
+// Keeps the number of spores in line with the map size and spore density,
+// telling clients about each one that appears or disappears
+func (r *Room) maintainSpores(tuning *config.GameConfig) {
+	spores := r.World.Spores
+
+	// The map may have shrunk, or the density gone down
+	excess := spores.Len() - tuning.SporeCount()
+	for _, id := range spores.Ids() {
+		spore, exists := spores.Get(id)
+		if !exists {
+			continue
+		}
+		if excess > 0 || spore.X > tuning.MapSize || spore.Y > tuning.MapSize {
+			if _, exists := spores.Take(id); exists {
+				excess--
+				r.Broadcast(&packets.Packet{Msg: packets.NewSporeConsumed(id)})
+			}
+		}
+	}
+
+	missing := min(tuning.SporeCount()-spores.Len(), maxSporesPerTick)
+	for i := 0; i < missing; i++ {
+		spore := &objects.Spore{
+			X:      r.rng.Float64() * tuning.MapSize,
+			Y:      r.rng.Float64() * tuning.MapSize,
+			Radius: tuning.SporeRadius,
+		}
+		id := spores.Add(spore)
+		r.Broadcast(&packets.Packet{Msg: packets.NewSpore(id, spore)})
+	}
+}
+
+// A fingerprint of where everything in every room is, for checking that two
+// runs of the same game ended up in the same place
+func (h *Hub) StateHash() uint64 {
+	hash := fnv.New64a()
+	for _, room := range h.Rooms.List() {
+		binary.Write(hash, binary.LittleEndian, room.Id)
+		binary.Write(hash, binary.LittleEndian, room.StateHash())
+	}
+	return hash.Sum64()
+}
+
+// A fingerprint of where everything in the room is
+func (r *Room) StateHash() uint64 {
+	hash := fnv.New64a()
+	write := func(values ...float64) {
+		for _, value := range values {
+			binary.Write(hash, binary.LittleEndian, math.Float64bits(value))
+		}
+	}
+
+	players := r.World.Players
+	for _, id := range players.Ids() {
+		if player, exists := players.Get(id); exists {
+			player = player.Snapshot()
+			binary.Write(hash, binary.LittleEndian, id)
+			write(player.X, player.Y, player.Radius, player.Direction)
+		}
+	}
+
+	spores := r.World.Spores
+	for _, id := range spores.Ids() {
+		if spore, exists := spores.Get(id); exists {
+			binary.Write(hash, binary.LittleEndian, id)
+			write(spore.X, spore.Y, spore.Radius)
+		}
+	}
+	return hash.Sum64()
+}
@@ -0,0 +1,61 @@
+package harness_test
+
+import (
+	"context"
+	"server/internal/server/config"
+	"testing"
+	"time"
+)
+
+// What a minute of the seeded game below hashes to. If a change to the
+// simulation moves this on purpose, update it.
+const goldenStateHash uint64 = 0xa00ade083e5ddcfd
+
+// Plays a minute with a room full of bots and a human who joins halfway and
+// sends some inputs, returning the state hash after every second
+func playWithBots(t *testing.T, ctx context.Context) []uint64 {
+	h := startManual(t, ctx, func(cfg *config.Config) {
+		cfg.Bots.MinPopulation = 6
+	})
+
+	ticksPerSecond := int(1 / h.Hub.Tuning().TickInterval.Seconds())
+	var hashes []uint64
+	for second := 0; second < 60; second++ {
+		if second == 30 {
+			human, _ := join(t, ctx, h, "judy")
+			// Keep up with what the server sends, or it would disconnect us
+			go func() {
+				for {
+					if _, err := human.Receive(ctx); err != nil {
+						return
+					}
+				}
+			}()
+			if err := human.Move(1); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := h.Step(ctx, ticksPerSecond); err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, h.Hub.StateHash())
+	}
+	return hashes
+}
+
+func TestBotsAreDeterministic(t *testing.T) {
+	// Long enough to register the human twice, which is slow under -race
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	first := playWithBots(t, ctx)
+	second := playWithBots(t, ctx)
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("runs differ after %d seconds: %x and %x", i+1, first[i], second[i])
+		}
+	}
+	if got := first[len(first)-1]; got != goldenStateHash {
+		t.Errorf("state hash is %#x, want %#x", got, goldenStateHash)
+	}
+}
@@ -0,0 +1,233 @@
+// Runs a real hub on in-memory storage, with clients that talk to it without a
+// network, so whole conversations (register, log in, move, watch the updates
+// come in) can be scripted in tests
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/internal/server"
+	"server/internal/server/clients"
+	"server/internal/server/config"
+	"server/pkg/packets"
+	"time"
+)
+
+var ErrDenied = errors.New("denied")
+
+// Longest Send waits for a message to be handled on a manual clock
+const settleTimeout = 5 * time.Second
+
+type Harness struct {
+	Hub *server.Hub
+
+	// Only set for a hub started with StartManual
+	Clock *server.ManualClock
+}
+
+// The defaults, but with nothing written to disk, no countdown on shutdown and
+// a fixed seed
+func Config() *config.Config {
+	cfg := config.Default()
+	cfg.Server.Storage = "memory"
+	cfg.Server.ShutdownCountdown = config.Duration{}
+	cfg.Server.Seed = 1
+	return cfg
+}
+
+// Starts a hub with the given config, or Config() if it's nil, and waits for
+// it to be ready
+func Start(ctx context.Context, cfg *config.Config) (*Harness, error) {
+	return start(ctx, cfg, nil)
+}
+
+// Like Start, but the hub's clock only moves when the harness steps it, so the
+// same seed and the same inputs between steps always lead to the same world
+func StartManual(ctx context.Context, cfg *config.Config, startTime time.Time) (*Harness, error) {
+	return start(ctx, cfg, server.NewManualClock(startTime))
+}
+
+func start(ctx context.Context, cfg *config.Config, clock *server.ManualClock) (*Harness, error) {
+	if cfg == nil {
+		cfg = Config()
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	h := &Harness{Hub: server.NewHub(cfg), Clock: clock}
+	if clock != nil {
+		h.Hub.Clock = clock
+	}
+	h.Hub.UseBots(clients.NewBotClient)
+	go h.Hub.Run()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for h.Hub.CheckReady(ctx) != nil {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return h, nil
+}
+
+// Disconnects everyone and stops the hub
+func (h *Harness) Stop(ctx context.Context) error {
+	return h.Hub.Shutdown(ctx, 0)
+}
+
+// Runs the given number of world ticks, see Hub.Step
+func (h *Harness) Step(ctx context.Context, ticks int) error {
+	for i := 0; i < ticks; i++ {
+		if err := h.Hub.Step(ctx); err != nil {
+			return fmt.Errorf("tick %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Connects a new client and waits for the hub to tell it its id
+func (h *Harness) Connect(ctx context.Context) (*Conn, error) {
+	conn := &Conn{Client: clients.NewMemoryClient(h.Hub), settle: h.Clock != nil}
+	h.Hub.Join(conn.Client)
+
+	id, err := Expect[*packets.Packet_Id](ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for id: %w", err)
+	}
+	conn.Id = id.Id.Id
+	return conn, nil
+}
+
+// One end of a conversation with the hub
+type Conn struct {
+	Client *clients.MemoryClient
+	Id     uint64
+
+	// Whether Send waits for the message to be handled, so that on a manual
+	// clock messages from different clients can't race each other
+	settle bool
+}
+
+func (c *Conn) Send(msg packets.Msg) error {
+	if err := c.Client.Send(msg); err != nil {
+		return err
+	}
+	if c.settle {
+		// Breaking the rate limits closes the client as it sends, and then
+		// there's nothing left to wait for
+		select {
+		case <-c.Client.Done():
+			return nil
+		default:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), settleTimeout)
+		defer cancel()
+		return server.Settle(ctx, c.Client)
+	}
+	return nil
+}
+
+func (c *Conn) Receive(ctx context.Context) (*packets.Packet, error) {
+	return c.Client.Receive(ctx)
+}
+
+func (c *Conn) Close() {
+	c.Client.Close("Closed by the harness")
+}
+
+func (c *Conn) Register(ctx context.Context, username, password string) error {
+	return c.request(ctx, packets.NewRegisterRequest(username, password))
+}
+
+func (c *Conn) Login(ctx context.Context, username, password string) error {
+	return c.request(ctx, packets.NewLoginRequest(username, password))
+}
+
+// Registers a new user and logs in as them, ending up in the game
+func (c *Conn) Join(ctx context.Context, username, password string) error {
+	if err := c.Register(ctx, username, password); err != nil {
+		return fmt.Errorf("registering: %w", err)
+	}
+	if err := c.Login(ctx, username, password); err != nil {
+		return fmt.Errorf("logging in: %w", err)
+	}
+	return nil
+}
+
+// Moves to the room with the given id, or any with space if it's 0
+func (c *Conn) JoinRoom(ctx context.Context, roomId uint64) error {
+	return c.request(ctx, packets.NewJoinRoom(roomId))
+}
+
+// Leaves the room for the lobby, or for a visitor, back to being connected
+func (c *Conn) LeaveRoom(ctx context.Context) error {
+	return c.request(ctx, packets.NewLeaveRoom())
+}
+
+// Joins the queue for a ranked match, which starts at the next step that finds
+// enough players for one
+func (c *Conn) JoinQueue(ctx context.Context) error {
+	return c.request(ctx, packets.NewJoinQueue())
+}
+
+func (c *Conn) LeaveQueue(ctx context.Context) error {
+	return c.request(ctx, packets.NewLeaveQueue())
+}
+
+// Heads in the given direction, with sequence 0 so it's always applied
+func (c *Conn) Move(direction float64) error {
+	return c.Send(packets.NewPlayerDirection(direction, 0, time.Now().UnixNano()))
+}
+
+// Sends the request and waits for the answer, returning an error wrapping
+// ErrDenied if the server says no
+func (c *Conn) request(ctx context.Context, msg packets.Msg) error {
+	if err := c.Send(msg); err != nil {
+		return err
+	}
+
+	for {
+		packet, err := c.Receive(ctx)
+		if err != nil {
+			return err
+		}
+		switch msg := packet.Msg.(type) {
+		case *packets.Packet_OkResponse:
+			if c.settle {
+				// Logging in enters the game just after saying ok
+				return server.Settle(ctx, c.Client)
+			}
+			return nil
+		case *packets.Packet_DenyResponse:
+			return fmt.Errorf("%w: %s", ErrDenied, msg.DenyResponse.Reason)
+		}
+	}
+}
+
+// Waits for the next packet carrying a T, skipping any others
+func Expect[T packets.Msg](ctx context.Context, c *Conn) (T, error) {
+	packet, err := ExpectFunc(ctx, c, func(msg T) bool { return true })
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return packet.Msg.(T), nil
+}
+
+// Waits for the next packet carrying a T that matches, skipping any others
+func ExpectFunc[T packets.Msg](ctx context.Context, c *Conn, match func(msg T) bool) (*packets.Packet, error) {
+	for {
+		packet, err := c.Receive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if msg, ok := packet.Msg.(T); ok && match(msg) {
+			return packet, nil
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package harness_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"server/internal/server/clients"
+	"server/internal/server/config"
+	"server/internal/server/harness"
+	"server/pkg/packets"
+	"testing"
+	"time"
+)
+
+// Starts a hub on a manual clock that's stopped when the test ends
+func startManual(t *testing.T, ctx context.Context, configure func(cfg *config.Config)) *harness.Harness {
+	t.Helper()
+	cfg := harness.Config()
+	if configure != nil {
+		configure(cfg)
+	}
+	h, err := harness.StartManual(ctx, cfg, time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("starting hub: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := h.Stop(ctx); err != nil {
+			t.Errorf("stopping hub: %v", err)
+		}
+	})
+	return h
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func connect(t *testing.T, ctx context.Context, h *harness.Harness) *harness.Conn {
+	t.Helper()
+	conn, err := h.Connect(ctx)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	return conn
+}
+
+// Connects, registers and logs in, returning the client's own player as the
+// server first described it
+func join(t *testing.T, ctx context.Context, h *harness.Harness, username string) (*harness.Conn, *packets.PlayerMessage) {
+	t.Helper()
+	conn := connect(t, ctx, h)
+	if err := conn.Join(ctx, username, "password"); err != nil {
+		t.Fatalf("joining as %s: %v", username, err)
+	}
+	packet, err := harness.ExpectFunc(ctx, conn, func(msg *packets.Packet_Player) bool {
+		return msg.Player.Id == conn.Id
+	})
+	if err != nil {
+		t.Fatalf("waiting for %s's player: %v", username, err)
+	}
+	return conn, packet.Msg.(*packets.Packet_Player).Player
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	ctx := testContext(t)
+	h := startManual(t, ctx, nil)
+
+	_, player := join(t, ctx, h, "alice")
+	if player.Name != "alice" {
+		t.Errorf("player is called %q, want alice", player.Name)
+	}
+	if player.Radius != h.Hub.Tuning().InitialRadius {
+		t.Errorf("player starts with radius %v, want %v", player.Radius, h.Hub.Tuning().InitialRadius)
+	}
+}
+
+func TestDenied(t *testing.T) {
+	ctx := testContext(t)
+	h := startManual(t, ctx, nil)
+	first := connect(t, ctx, h)
+	if err := first.Register(ctx, "bob", "password"); err != nil {
+		t.Fatalf("registering: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		request func(conn *harness.Conn) error
+	}{
+		{"taken username", func(conn *harness.Conn) error { return conn.Register(ctx, "bob", "other") }},
+		{"taken username in another case", func(conn *harness.Conn) error { return conn.Register(ctx, "BOB", "other") }},
+		{"invalid username", func(conn *harness.Conn) error { return conn.Register(ctx, "", "password") }},
+		{"wrong password", func(conn *harness.Conn) error { return conn.Login(ctx, "bob", "wrong") }},
+		{"unknown user", func(conn *harness.Conn) error { return conn.Login(ctx, "nobody", "password") }},
+		{"ranked as a visitor", func(conn *harness.Conn) error { return conn.JoinQueue(ctx) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := connect(t, ctx, h)
+			defer conn.Close()
+			if err := test.request(conn); !errors.Is(err, harness.ErrDenied) {
+				t.Errorf("got %v, want %v", err, harness.ErrDenied)
+			}
+		})
+	}
+}
+
+func TestSpectateWhileLoggingIn(t *testing.T) {
+	ctx := testContext(t)
+	h := startManual(t, ctx, nil)
+	conn := connect(t, ctx, h)
+	if err := conn.Register(ctx, "judy", "password"); err != nil {
+		t.Fatalf("registering: %v", err)
+	}
+
+	// Both are handled before the password has been checked
+	for _, msg := range []packets.Msg{
+		packets.NewLoginRequest("judy", "password"),
+		packets.NewSpectate(packets.SpectateMessage_FOLLOW_LEADER, 0),
+	} {
+		if err := conn.Client.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := harness.Expect[*packets.Packet_DenyResponse](ctx, conn); err != nil {
+		t.Fatalf("waiting for spectating to be turned down: %v", err)
+	}
+	if _, err := harness.Expect[*packets.Packet_OkResponse](ctx, conn); err != nil {
+		t.Fatalf("waiting for the login: %v", err)
+	}
+	if _, err := harness.ExpectFunc(ctx, conn, func(msg *packets.Packet_Player) bool {
+		return msg.Player.Id == conn.Id
+	}); err != nil {
+		t.Fatalf("waiting for judy's player: %v", err)
+	}
+	if state := conn.Client.StateName(); state != "Ingame" {
+		t.Errorf("ended up %s, want Ingame", state)
+	}
+}
+
+func TestMove(t *testing.T) {
+	ctx := testContext(t)
+	h := startManual(t, ctx, nil)
+	conn, start := join(t, ctx, h, "carol")
+
+	// Head for the middle, so the edge of the map can't get in the way
+	middle := h.Hub.Tuning().MapSize / 2
+	if err := conn.Move(math.Atan2(middle-start.Y, middle-start.X)); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Step(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	// Updates to the same player replace each other while they're waiting to be
+	// received, so look for the one that's gone the whole way
+	want := h.Hub.Tuning().PlayerSpeed * 5 * h.Hub.Tuning().TickInterval.Seconds()
+	packet, err := harness.ExpectFunc(ctx, conn, func(msg *packets.Packet_Player) bool {
+		return msg.Player.Id == conn.Id && math.Abs(math.Hypot(msg.Player.X-start.X, msg.Player.Y-start.Y)-want) < 1e-6
+	})
+	if err != nil {
+		t.Fatalf("waiting to have moved %v: %v", want, err)
+	}
+	moved := packet.Msg.(*packets.Packet_Player).Player
+	if math.Hypot(moved.X-middle, moved.Y-middle) >= math.Hypot(start.X-middle, start.Y-middle) {
+		t.Errorf("moved from (%v, %v) to (%v, %v), away from the middle", start.X, start.Y, moved.X, moved.Y)
+	}
+}
+
+// Waits for the client's own player to reach the radius
+func expectRadius(t *testing.T, ctx context.Context, conn *harness.Conn, radius float64) {
+	t.Helper()
+	_, err := harness.ExpectFunc(ctx, conn, func(msg *packets.Packet_Player) bool {
+		return msg.Player.Id == conn.Id && math.Abs(msg.Player.Radius-radius) < 1e-6
+	})
+	if err != nil {
+		t.Fatalf("waiting for radius %v: %v", radius, err)
+	}
+}
+
+func TestEat(t *testing.T) {
+	ctx := testContext(t)
+	// A map so small that everyone touches everything, no rewinding so claims
+	// are judged against where things are now, and room to claim every spore
+	// at once
+	h := startManual(t, ctx, func(cfg *config.Config) {
+		cfg.Game.MapSize = 10
+		cfg.Game.SporeDensity = 4000
+		cfg.Game.InterpolationDelay.Duration = 0
+		cfg.Network.RateLimits.Other.Burst = 100
+	})
+	// Let the room put its spores down
+	if err := h.Step(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	spores := h.Hub.Rooms.List()[0].World.Spores.Ids()
+	if len(spores) == 0 {
+		t.Fatal("no spores to eat")
+	}
+
+	eater, _ := join(t, ctx, h, "dave")
+	target, _ := join(t, ctx, h, "erin")
+	tuning := h.Hub.Tuning()
+
+	// Too small to eat anyone yet
+	if err := eater.Send(packets.NewPlayerConsumed(target.Id)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each spore only goes once
+	for _, id := range append(spores, spores[0]) {
+		if err := eater.Send(packets.NewSporeConsumed(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	area := tuning.InitialRadius*tuning.InitialRadius + float64(len(spores))*tuning.SporeRadius*tuning.SporeRadius
+	expectRadius(t, ctx, eater, math.Sqrt(area))
+
+	if err := eater.Send(packets.NewPlayerConsumed(target.Id)); err != nil {
+		t.Fatal(err)
+	}
+	packet, err := harness.ExpectFunc(ctx, target, func(msg *packets.Packet_PlayerConsumed) bool {
+		return msg.PlayerConsumed.PlayerId == target.Id
+	})
+	if err != nil {
+		t.Fatalf("waiting to be eaten: %v", err)
+	}
+	if packet.SenderId != eater.Id {
+		t.Errorf("eaten by %d, want %d", packet.SenderId, eater.Id)
+	}
+	expectRadius(t, ctx, eater, math.Sqrt(area+tuning.InitialRadius*tuning.InitialRadius))
+}
+
+func TestChat(t *testing.T) {
+	ctx := testContext(t)
+	h := startManual(t, ctx, nil)
+	sender, _ := join(t, ctx, h, "frank")
+	receiver, _ := join(t, ctx, h, "grace")
+
+	if err := sender.Send(packets.NewChat("hello")); err != nil {
+		t.Fatal(err)
+	}
+	packet, err := harness.ExpectFunc(ctx, receiver, func(msg *packets.Packet_Chat) bool { return true })
+	if err != nil {
+		t.Fatalf("waiting for chat: %v", err)
+	}
+	if packet.SenderId != sender.Id || packet.Msg.(*packets.Packet_Chat).Chat.Msg != "hello" {
+		t.Errorf("got %q from %d, want hello from %d", packet.Msg.(*packets.Packet_Chat).Chat.Msg, packet.SenderId, sender.Id)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	ctx := testContext(t)
+	h := startManual(t, ctx, nil)
+	leaver, _ := join(t, ctx, h, "heidi")
+	stayer, _ := join(t, ctx, h, "ivan")
+
+	leaver.Close()
+	if _, err := harness.ExpectFunc(ctx, stayer, func(msg *packets.Packet_Id) bool {
+		return msg.Id.Id == leaver.Id
+	}); err != nil {
+		t.Fatalf("waiting to hear the player left: %v", err)
+	}
+	for {
+		_, err := leaver.Receive(ctx)
+		if errors.Is(err, clients.ErrClientClosed) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("receiving after closing got %v, want %v", err, clients.ErrClientClosed)
+		}
+	}
+	if err := leaver.Send(packets.NewChat("still here?")); !errors.Is(err, clients.ErrClientClosed) {
+		t.Errorf("sending after closing got %v, want %v", err, clients.ErrClientClosed)
+	}
+}
+
+func TestBan(t *testing.T) {
+	ctx := testContext(t)
+	h := startManual(t, ctx, nil)
+	conn, _ := join(t, ctx, h, "mallory")
+
+	if err := h.Hub.Ban(ctx, conn.Client.UserId(), "Cheating"); err != nil {
+		t.Fatalf("banning: %v", err)
+	}
+	select {
+	case <-conn.Client.Done():
+	default:
+		t.Fatal("still connected after the ban")
+	}
+
+	again := connect(t, ctx, h)
+	if err := again.Login(ctx, "mallory", "password"); !errors.Is(err, harness.ErrDenied) {
+		t.Errorf("logging in after the ban got %v, want %v", err, harness.ErrDenied)
+	}
+}
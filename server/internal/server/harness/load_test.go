@@ -0,0 +1,153 @@
+package harness_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/server/clients"
+	"server/internal/server/harness"
+	"server/pkg/bot"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Real websocket clients all playing at once, next to the server's own bots, so
+// the race detector gets to see every goroutine a connection has
+func TestLoad(t *testing.T) {
+	if testing.Short() {
+		t.Skip("takes a few seconds")
+	}
+	const humans = 12
+	const playFor = 3 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cfg := harness.Config()
+	// Small rooms, so the humans spread over several and push the bots out of
+	// the first
+	cfg.Rooms.MaxPlayers = 8
+	cfg.Bots.MinPopulation = 6
+	h, err := harness.Start(ctx, cfg)
+	if err != nil {
+		t.Fatalf("starting hub: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := h.Stop(ctx); err != nil {
+			t.Errorf("stopping hub: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.Hub.Serve(clients.NewWebSocketClient, w, r)
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	// Wait for the first bots, so the humans join a room that's already busy
+	if err := poll(ctx, func() bool { return population(h) >= cfg.Bots.MinPopulation }); err != nil {
+		t.Fatalf("waiting for bots: %v", err)
+	}
+
+	// Logging in is slow, so everyone does that first and then they all play
+	// at once
+	var loggedIn, wg sync.WaitGroup
+	start := make(chan struct{})
+	stats := make([]bot.Stats, humans)
+	errs := make([]error, humans)
+	for i := range humans {
+		loggedIn.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats[i], errs[i] = play(ctx, url, fmt.Sprintf("human%d", i), rand.New(rand.NewSource(int64(i))), &loggedIn, start, playFor)
+		}()
+	}
+	loggedIn.Wait()
+	close(start)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("human%d: %v", i, err)
+			continue
+		}
+		if stats[i].OwnUpdates == 0 || stats[i].OtherUpdates == 0 {
+			t.Errorf("human%d saw %d updates about itself and %d about others, want some of each", i, stats[i].OwnUpdates, stats[i].OtherUpdates)
+		}
+		if stats[i].LastAcked == 0 {
+			t.Errorf("human%d sent %d inputs and none were acknowledged", i, stats[i].InputsSent)
+		}
+	}
+
+	// Once everyone has gone the bots fill the first room back up
+	if err := poll(ctx, func() bool {
+		return h.Hub.Clients.Len() == cfg.Bots.MinPopulation && population(h) == cfg.Bots.MinPopulation
+	}); err != nil {
+		t.Errorf("waiting for only bots to be left: %v", err)
+	}
+}
+
+// Connects, registers and logs in, then wanders about from when start is closed
+// until the time is up
+func play(ctx context.Context, url, username string, rng *rand.Rand, loggedIn *sync.WaitGroup, start <-chan struct{}, playFor time.Duration) (bot.Stats, error) {
+	b, err := login(ctx, url, username)
+	loggedIn.Done()
+	if err != nil {
+		return bot.Stats{}, err
+	}
+	defer b.Close()
+
+	select {
+	case <-start:
+	case <-ctx.Done():
+		return bot.Stats{}, ctx.Err()
+	}
+	playCtx, cancel := context.WithTimeout(ctx, playFor)
+	defer cancel()
+	if err := b.Run(playCtx, bot.Wander(rng, 500*time.Millisecond), 50*time.Millisecond); err != nil {
+		return bot.Stats{}, fmt.Errorf("playing: %w", err)
+	}
+	return b.Stats(), nil
+}
+
+func login(ctx context.Context, url, username string) (*bot.Bot, error) {
+	b, err := bot.Dial(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("connecting: %w", err)
+	}
+	if err := b.Register(ctx, username, "password"); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("registering: %w", err)
+	}
+	if err := b.Login(ctx, username, "password"); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("logging in: %w", err)
+	}
+	return b, nil
+}
+
+// How many are playing in the first room
+func population(h *harness.Harness) int {
+	return h.Hub.Rooms.List()[0].World.Players.Len()
+}
+
+// Polls until the condition holds or the context is done
+func poll(ctx context.Context, condition func() bool) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for !condition() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
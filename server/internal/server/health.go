@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// How long the readiness check waits for the database to answer
+const readyPingTimeout = time.Second
+
+// Returns nil if the hub can take new players: its loop is running (which
+// means the schema has been applied), the database answers, and we aren't
+// shutting down
+func (h *Hub) CheckReady(ctx context.Context) error {
+	if !h.running.Load() {
+		return errors.New("hub is not running")
+	}
+	if h.Draining() {
+		return errors.New("server is shutting down")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readyPingTimeout)
+	defer cancel()
+	if err := h.store.Ping(ctx); err != nil {
+		return fmt.Errorf("database is unavailable: %w", err)
+	}
+	return nil
+}
+
+// Answers as long as the process is up
+func (h *Hub) ServeHealth(writer http.ResponseWriter, request *http.Request) {
+	writer.Write([]byte("ok\n"))
+}
+
+func (h *Hub) ServeReady(writer http.ResponseWriter, request *http.Request) {
+	if err := h.CheckReady(request.Context()); err != nil {
+		h.Logger.Debug("Not ready", "error", err)
+		http.Error(writer, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	writer.Write([]byte("ok\n"))
+}
@@ -2,26 +2,61 @@ package server
 
 import (
 	"context"
-	"database/sql"
-	_ "embed"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
-	"server/internal/server/db"
+	"os"
+	"server/internal/server/config"
 	"server/internal/server/objects"
+	"server/internal/server/store"
 	"server/pkg/packets"
+	"sync/atomic"
+	"time"
 
-	_ "modernc.org/sqlite"
+	"github.com/gorilla/websocket"
 )
 
+// What the hub, its rooms and the admin API need from every client
 type ClientInterface interface {
 	Id() uint64
 	ProcessMessage(senderId uint64, msg packets.Msg)
+
+	// Only call this from the client's own goroutine, i.e. from its state or
+	// something passed to Deliver
 	SetState(state ClientStateHandler)
 
 	// A reference to the database transaction context
 	DbTx() *DbTx
+
+	// The room the client is in, nil if it isn't in one. Set by Rooms as the
+	// client joins and leaves.
+	Room() *Room
+	SetRoom(room *Room)
+
+	// The world of the client's room, nil outside a room
 	SharedGameObjects() *SharedGameObjects
 
+	// The smoothed round-trip time to the client
+	Rtt() time.Duration
+
+	// Logs with the client's id, user id and current state attached
+	Logger() *slog.Logger
+
+	// The user the client is logged in as, 0 if none
+	UserId() int64
+
+	// The name of the current state, "None" if there isn't one
+	StateName() string
+
+	// Queues fn behind the client's messages, which are all handled one at a
+	// time on the client's own goroutine, so it can safely touch the state.
+	// Dropped if the client closes first.
+	Deliver(fn func())
+
+	// Queues a world tick of delta seconds for the state, if it's a TickHandler
+	Tick(delta float64)
+
 	Initialize(id uint64)
 	SocketSend(msg packets.Msg)
 	SocketSendAs(senderId uint64, msg packets.Msg)
@@ -30,91 +65,231 @@ type ClientInterface interface {
 	ReadPump()
 	WritePump()
 	Close(reason string)
+
+	// Like Close, but tells the client why with a websocket close code
+	CloseWithCode(code int, reason string)
+}
+
+// What a state needs from its client on top of what the hub does: the hub's
+// services, and what only the state gets to change
+type StateClient interface {
+	ClientInterface
+
+	Config() *config.Config
+	Rooms() *Rooms
+	Matchmaker() *Matchmaker
+
+	// The gameplay tuning in effect in the client's room for the current tick,
+	// or the hub's outside a room
+	Tuning() *config.GameConfig
+
+	// Records which user the client is logged in as, 0 if none
+	SetUserId(userId int64)
+
+	// Whether the client's user has been muted
+	Muted() bool
+
+	// Runs the job on the hub's auth pool, returning false if it's too busy
+	SubmitAuth(job func()) bool
 }
 
 type ClientStateHandler interface {
 	Name() string
-	SetClient(client ClientInterface)
+	SetClient(client StateClient)
 	HandleMessage(senderId uint64, msg packets.Msg)
 	OnExit()
 	OnEnter()
 }
 
-type Hub struct {
-	Clients          *objects.SharedCollection[ClientInterface]
-	BroadcastChan    chan *packets.Packet
-	RegisterChan     chan ClientInterface
-	UnregisterChan   chan ClientInterface
-	dbPool           *sql.DB
-	SharedGameObject *SharedGameObjects
-}
-type DbTx struct {
-	Ctx     context.Context
-	Queries *db.Queries
+// A state with something to simulate every world tick
+type TickHandler interface {
+	OnTick(delta float64)
 }
 
-func (h *Hub) NewDbTx() *DbTx {
-	return &DbTx{
-		Ctx:     context.Background(),
-		Queries: db.New(h.dbPool),
-	}
+type Hub struct {
+	Clients        *objects.SharedCollection[ClientInterface]
+	RegisterChan   chan ClientInterface
+	UnregisterChan chan ClientInterface
+	Rooms          *Rooms
+	Matchmaker     *Matchmaker
+	store          store.Store
+	Config         *config.Config
+	Logger         *slog.Logger
+
+	// What every room takes the time from, and the randomness each room's is
+	// derived from. Replace the clock before the hub runs to drive the game
+	// with Hub.Step.
+	Clock  Clock
+	Random Randomness
+
+	// The tuning new rooms start with
+	tuning atomic.Pointer[config.GameConfig]
+
+	// Set while the hub loop is running, which is only after the database has been migrated
+	running atomic.Bool
+
+	mutes mutes
+	auth  *authPool
+	bots  *botKeeper
+
+	// Set once we've started shutting down, new connections are refused from then on
+	draining atomic.Bool
+	quit     chan struct{}
+	stopped  chan struct{}
 }
 
 type SharedGameObjects struct {
 	Players *objects.SharedCollection[*objects.Player]
-}
+	Spores  *objects.SharedCollection[*objects.Spore]
 
-var (
-	//go:embed db/schema.sql
-	schemaGenSql string
-)
+	// What the simulation takes the time and its random numbers from
+	Clock  Clock
+	Random Randomness
+}
 
-func NewHub() *Hub {
-	dbPool, err := sql.Open("sqlite", "server.db")
+func NewHub(cfg *config.Config) *Hub {
+	logger := slog.Default().With("component", "hub")
+	storage, err := store.Open(cfg.Server)
 	if err != nil {
-		log.Fatal(err)
+		logger.Error("Failed to open the storage", "storage", cfg.Server.Storage, "error", err)
+		os.Exit(1)
 	}
-	return &Hub{
+	seed := cfg.Server.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	logger.Info("Seeded the simulation", "seed", seed)
+
+	channelSize := cfg.Network.HubChannelSize
+	hub := &Hub{
 		Clients:        objects.NewSharedCollection[ClientInterface](),
-		BroadcastChan:  make(chan *packets.Packet, 256),
-		RegisterChan:   make(chan ClientInterface, 256),
-		UnregisterChan: make(chan ClientInterface, 256),
-		dbPool:         dbPool,
-		SharedGameObject: &SharedGameObjects{
-			Players: objects.NewSharedCollection[*objects.Player](),
-		},
+		RegisterChan:   make(chan ClientInterface, channelSize),
+		UnregisterChan: make(chan ClientInterface, channelSize),
+		store:          storage,
+		Config:         cfg,
+		Logger:         logger,
+		mutes:          mutes{until: make(map[int64]time.Time)},
+		quit:           make(chan struct{}),
+		stopped:        make(chan struct{}),
+		Clock:          RealClock,
+		Random:         Randomness{Seed: seed},
 	}
+	hub.Rooms = newRooms(hub)
+	hub.Matchmaker = newMatchmaker(hub)
+	hub.bots = &botKeeper{hub: hub}
+	hub.auth = newAuthPool(cfg.Server.AuthWorkers, cfg.Server.AuthQueueSize, hub.quit)
+	tuning := cfg.Game
+	hub.tuning.Store(&tuning)
+	return hub
 }
 
 func (h *Hub) Run() {
-	log.Println("Hub is running...")
-	if _, err := h.dbPool.ExecContext(context.Background(), schemaGenSql); err != nil {
-		log.Fatal(err)
+	defer close(h.stopped)
+	changes, err := h.store.Migrate(context.Background())
+	for _, change := range changes {
+		h.Logger.Info(change)
+	}
+	if err != nil {
+		h.Logger.Error("Failed to migrate the database", "error", err)
+		os.Exit(1)
 	}
+	// There's always somewhere to play
+	h.Rooms.Open()
+	defer h.Rooms.wait()
+	go h.Matchmaker.run()
+	go h.bots.run()
+
+	h.running.Store(true)
+	defer h.running.Store(false)
+	h.Logger.Info("Hub is running")
 	for {
 		select {
 		case client := <-h.RegisterChan:
 			client.Initialize(h.Clients.Add(client))
 		case client := <-h.UnregisterChan:
 			h.Clients.Remove(client.Id())
-		case packet := <-h.BroadcastChan:
-			h.Clients.ForEach(func(id uint64, client ClientInterface) {
-				if id != packet.SenderId {
-					client.ProcessMessage(packet.SenderId, packet.Msg)
-				}
-			})
+		case <-h.quit:
+			h.Logger.Info("Hub has stopped")
+			return
 		}
 	}
 }
 
+func (h *Hub) Draining() bool {
+	return h.draining.Load()
+}
+
+// Sends a chat message from the server to every connected client
+func (h *Hub) Announce(msg string) {
+	h.Clients.ForEach(func(id uint64, client ClientInterface) {
+		client.SocketSendAs(0, packets.NewChat(msg))
+	})
+}
+
+// Stops accepting connections, counts down in chat so players know what's
+// coming, then disconnects everyone (saving their progress as their states
+// exit) before stopping the hub and closing the database. Returns early with
+// the context's error if it's done before all of that has happened.
+func (h *Hub) Shutdown(ctx context.Context, countdown time.Duration) error {
+	if !h.draining.CompareAndSwap(false, true) {
+		return errors.New("hub is already shutting down")
+	}
+
+	h.Logger.Info("Shutting down", "countdown", countdown)
+	for remaining := countdown.Round(time.Second); remaining > 0; remaining -= time.Second {
+		if remaining == countdown.Round(time.Second) || remaining <= 5*time.Second || remaining%(10*time.Second) == 0 {
+			h.Announce(fmt.Sprintf("Server is shutting down in %v", remaining))
+		}
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	h.Clients.ForEach(func(id uint64, client ClientInterface) {
+		client.CloseWithCode(websocket.CloseGoingAway, "Server is shutting down")
+	})
+
+	// Clients unregister themselves through the hub loop, so keep it running until they're all gone
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for h.Clients.Len() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	close(h.quit)
+	select {
+	case <-h.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	h.Logger.Info("Closing the database")
+	return h.store.Close()
+}
+
 func (h *Hub) Serve(getNewClient func(*Hub, http.ResponseWriter, *http.Request) (ClientInterface, error), writer http.ResponseWriter, request *http.Request) {
-	log.Printf("New connection attempt from %s", request.RemoteAddr)
+	h.Logger.Debug("New connection attempt", "remote_addr", request.RemoteAddr)
+	if h.Draining() {
+		http.Error(writer, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
 	client, err := getNewClient(h, writer, request)
 	if err != nil {
-		log.Printf("Failed to create client: %v", err)
+		h.Logger.Warn("Failed to create client", "remote_addr", request.RemoteAddr, "error", err)
 		return
 	}
 
+	h.Join(client)
+}
+
+// Starts the client's pumps and hands it to the hub to be initialized
+func (h *Hub) Join(client ClientInterface) {
 	go client.WritePump()
 	go client.ReadPump()
 	h.RegisterChan <- client
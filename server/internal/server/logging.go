@@ -0,0 +1,20 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"server/internal/server/config"
+)
+
+// Builds the logger described by the config, writing to w. The config is
+// assumed to have been validated.
+func NewLogger(cfg config.ServerConfig, w io.Writer) *slog.Logger {
+	var level slog.Level
+	level.UnmarshalText([]byte(cfg.LogLevel))
+	options := &slog.HandlerOptions{Level: level}
+
+	if cfg.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
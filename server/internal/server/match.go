@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"server/internal/server/matchmaking"
+	"server/internal/server/store"
+	"server/pkg/packets"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// A ranked match, played in a room of its own by the players the matchmaker
+// put together
+type Match struct {
+	Players []MatchPlayer
+	Ends    time.Time
+
+	over atomic.Bool
+}
+
+// Someone in a ranked match, as they were when it started
+type MatchPlayer struct {
+	ClientId uint64
+	UserId   int64
+	Name     string
+	Rating   matchmaking.Rating
+}
+
+func (m *Match) includes(clientId uint64) bool {
+	return slices.ContainsFunc(m.Players, func(player MatchPlayer) bool {
+		return player.ClientId == clientId
+	})
+}
+
+func (m *Match) Over() bool {
+	return m.over.Load()
+}
+
+// Ends the room's match once its time is up. Everyone still playing is placed
+// by size, and anyone who left shares last place. Their new ratings are saved
+// and everyone in the room is told the result.
+func (r *Room) checkMatch() {
+	match := r.match
+	if match == nil || match.Over() || r.World.Clock.Now().Before(match.Ends) {
+		return
+	}
+	match.over.Store(true)
+
+	scores := make([]float64, len(match.Players))
+	for i, player := range match.Players {
+		scores[i] = -1
+		if p, exists := r.World.Players.Get(player.ClientId); exists {
+			scores[i] = p.Snapshot().Radius
+		}
+	}
+	places := matchmaking.Places(scores)
+
+	results := make([]matchmaking.Result, len(match.Players))
+	for i, player := range match.Players {
+		results[i] = matchmaking.Result{Rating: player.Rating, Place: places[i]}
+	}
+	ratings := r.hub.Matchmaker.ratings.Update(results)
+	r.saveRatings(match, ratings)
+
+	placements := make([]*packets.MatchPlacement, len(match.Players))
+	for i, player := range match.Players {
+		placements[i] = &packets.MatchPlacement{
+			PlayerId:     player.ClientId,
+			Name:         player.Name,
+			Place:        uint32(places[i]),
+			Rating:       ratings[i].Rating,
+			RatingChange: ratings[i].Rating - player.Rating.Rating,
+		}
+	}
+	slices.SortStableFunc(placements, func(a, b *packets.MatchPlacement) int {
+		return int(a.Place) - int(b.Place)
+	})
+	r.logger.Info("Ranked match over", "winner", placements[0].Name)
+	r.Broadcast(&packets.Packet{Msg: packets.NewMatchResult(placements)})
+}
+
+// Saves everyone's new rating together, so a failure doesn't leave only some
+// of them updated
+func (r *Room) saveRatings(match *Match, ratings []matchmaking.Rating) {
+	ctx := store.WithLogger(context.Background(), func() *slog.Logger { return r.logger })
+	ctx, cancel := context.WithTimeout(ctx, r.hub.Config.Server.DbTimeout.Duration)
+	defer cancel()
+
+	err := r.hub.store.WithTx(ctx, func(tx store.Tx) error {
+		for i, player := range match.Players {
+			err := tx.RecordMatch(ctx, store.MatchResult{
+				UserId:    player.UserId,
+				Rating:    ratings[i].Rating,
+				Deviation: ratings[i].Deviation,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to save ratings", "error", err)
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"server/internal/server/matchmaking"
+	"server/internal/server/metrics"
+	"server/pkg/packets"
+	"sync"
+	"time"
+)
+
+// Ranked play: logged in players wait in the queue until there are enough of
+// a similar rating, then get a room of their own for a match. Their ratings
+// are updated from how they placed when it ends.
+type Matchmaker struct {
+	hub     *Hub
+	ratings matchmaking.RatingSettings
+
+	mux     sync.Mutex
+	queue   *matchmaking.Queue
+	waiting map[uint64]*QueueEntry
+}
+
+// A client waiting for a match
+type QueueEntry struct {
+	MatchPlayer
+	Client ClientInterface
+
+	// Called with the match's room once one is found, from the matchmaker's
+	// goroutine. The client has already been taken out of the queue by then.
+	Start func(room *Room)
+}
+
+func newMatchmaker(hub *Hub) *Matchmaker {
+	settings, ratings := matchmaking.FromConfig(hub.Config.Matchmaking)
+	return &Matchmaker{
+		hub:     hub,
+		ratings: ratings,
+		queue:   matchmaking.NewQueue(settings),
+		waiting: make(map[uint64]*QueueEntry),
+	}
+}
+
+// The rating of a player who hasn't finished a ranked match yet
+func (m *Matchmaker) InitialRating() matchmaking.Rating {
+	return m.ratings.New()
+}
+
+// Puts the client at the back of the queue, returning false if it's already in
+func (m *Matchmaker) Enqueue(entry *QueueEntry) bool {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	ticket := matchmaking.Ticket{Id: entry.ClientId, Rating: entry.Rating.Rating, Joined: m.hub.Clock.Now()}
+	if !m.queue.Add(ticket) {
+		return false
+	}
+	m.waiting[entry.ClientId] = entry
+	metrics.QueueLength.Set(float64(m.queue.Len()))
+	return true
+}
+
+// Takes the client out of the queue, returning false if it wasn't in it
+func (m *Matchmaker) Dequeue(clientId uint64) bool {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if !m.queue.Remove(clientId) {
+		return false
+	}
+	delete(m.waiting, clientId)
+	metrics.QueueLength.Set(float64(m.queue.Len()))
+	return true
+}
+
+// Checks the queue for matches every interval until the hub stops
+func (m *Matchmaker) run() {
+	ticker := m.hub.Clock.NewTicker(m.hub.Config.Matchmaking.Interval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			m.match()
+		case <-m.hub.quit:
+			return
+		}
+	}
+}
+
+// Starts every match the queue has players for, and tells everyone still
+// waiting how it's going. Returns the players whose matches started.
+func (m *Matchmaker) match() []*QueueEntry {
+	m.mux.Lock()
+	now := m.hub.Clock.Now()
+
+	// Matches need rooms, and there are only so many
+	free := m.hub.Config.Rooms.MaxRooms - len(m.hub.Rooms.List())
+	var groups [][]*QueueEntry
+	for _, tickets := range m.queue.Match(now) {
+		if len(groups) == free {
+			for _, ticket := range tickets {
+				m.queue.Add(ticket)
+			}
+			continue
+		}
+		group := make([]*QueueEntry, len(tickets))
+		for i, ticket := range tickets {
+			group[i] = m.waiting[ticket.Id]
+			delete(m.waiting, ticket.Id)
+			metrics.QueueWait.Observe(now.Sub(ticket.Joined).Seconds())
+		}
+		groups = append(groups, group)
+	}
+
+	waiting := m.queue.Len()
+	metrics.QueueLength.Set(float64(waiting))
+	for _, ticket := range m.queue.Tickets() {
+		waited := now.Sub(ticket.Joined)
+		status := packets.NewQueueStatus(waiting, ticket.Rating, m.queue.Settings.Gap(waited), waited)
+		m.waiting[ticket.Id].Client.SocketSendAs(0, status)
+	}
+	m.mux.Unlock()
+
+	var started []*QueueEntry
+	for _, group := range groups {
+		m.start(group, now)
+		started = append(started, group...)
+	}
+	return started
+}
+
+func (m *Matchmaker) start(group []*QueueEntry, now time.Time) {
+	match := &Match{
+		Players: make([]MatchPlayer, len(group)),
+		Ends:    now.Add(m.hub.Config.Matchmaking.MatchDuration.Duration),
+	}
+	for i, entry := range group {
+		match.Players[i] = entry.MatchPlayer
+	}
+
+	room := m.hub.Rooms.OpenMatch(match)
+	room.logger.Info("Starting a ranked match", "players", len(group), "ends", match.Ends)
+	metrics.Matches.Inc()
+	for _, entry := range group {
+		entry.Start(room)
+	}
+}
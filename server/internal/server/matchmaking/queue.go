@@ -0,0 +1,155 @@
+package matchmaking
+
+import (
+	"math"
+	"server/internal/server/config"
+	"slices"
+	"time"
+)
+
+// The trade-off between waiting and playing evenly matched opponents. Players
+// start off only accepting opponents close to their own rating, and become
+// less fussy the longer they wait.
+type Settings struct {
+	// Players in a full match, and the fewest a match can start with once
+	// someone has waited MaxWait
+	MatchSize    int
+	MinMatchSize int
+	MaxWait      time.Duration
+
+	// How far from their own rating players accept opponents straight away,
+	// how much further for each second they wait, and the furthest they ever do
+	InitialGap float64
+	GapGrowth  float64
+	MaxGap     float64
+}
+
+// The queue and rating settings in the config
+func FromConfig(cfg config.MatchmakingConfig) (Settings, RatingSettings) {
+	return Settings{
+		MatchSize:    cfg.MatchSize,
+		MinMatchSize: cfg.MinMatchSize,
+		MaxWait:      cfg.MaxWait.Duration,
+		InitialGap:   cfg.InitialRatingGap,
+		GapGrowth:    cfg.RatingGapGrowth,
+		MaxGap:       cfg.MaxRatingGap,
+	}, RatingSettings{
+		Initial:          cfg.InitialRating,
+		InitialDeviation: cfg.InitialDeviation,
+		MinDeviation:     cfg.MinDeviation,
+	}
+}
+
+// How far from their rating a player who has waited this long accepts opponents
+func (s Settings) Gap(waited time.Duration) float64 {
+	return math.Min(s.InitialGap+s.GapGrowth*waited.Seconds(), s.MaxGap)
+}
+
+// A player waiting for a match
+type Ticket struct {
+	Id     uint64
+	Rating float64
+	Joined time.Time
+}
+
+// Players waiting for a match, in the order they joined. Not safe for
+// concurrent use.
+type Queue struct {
+	Settings Settings
+	tickets  []Ticket
+}
+
+func NewQueue(settings Settings) *Queue {
+	return &Queue{Settings: settings}
+}
+
+// Adds the ticket behind everyone who joined before it, returning false if its
+// id is already queued
+func (q *Queue) Add(ticket Ticket) bool {
+	if q.index(ticket.Id) >= 0 {
+		return false
+	}
+	i := len(q.tickets)
+	for i > 0 && q.tickets[i-1].Joined.After(ticket.Joined) {
+		i--
+	}
+	q.tickets = slices.Insert(q.tickets, i, ticket)
+	return true
+}
+
+// Takes the ticket with the id out of the queue, returning false if it wasn't in
+func (q *Queue) Remove(id uint64) bool {
+	i := q.index(id)
+	if i < 0 {
+		return false
+	}
+	q.tickets = slices.Delete(q.tickets, i, i+1)
+	return true
+}
+
+func (q *Queue) index(id uint64) int {
+	return slices.IndexFunc(q.tickets, func(ticket Ticket) bool {
+		return ticket.Id == id
+	})
+}
+
+func (q *Queue) Len() int {
+	return len(q.tickets)
+}
+
+// The waiting tickets, longest waiting first
+func (q *Queue) Tickets() []Ticket {
+	return slices.Clone(q.tickets)
+}
+
+// Takes out of the queue every group that can start a match now, and returns
+// them. Whoever has waited longest gets matched first, with the players
+// closest to their rating within their current gap. A group is full at
+// MatchSize, but once its first player has waited MaxWait it may start with as
+// few as MinMatchSize.
+func (q *Queue) Match(now time.Time) [][]Ticket {
+	var groups [][]Ticket
+	taken := make(map[uint64]bool)
+	for _, anchor := range q.tickets {
+		if taken[anchor.Id] {
+			continue
+		}
+		waited := now.Sub(anchor.Joined)
+		gap := q.Settings.Gap(waited)
+
+		var candidates []Ticket
+		for _, other := range q.tickets {
+			if other.Id != anchor.Id && !taken[other.Id] && math.Abs(other.Rating-anchor.Rating) <= gap {
+				candidates = append(candidates, other)
+			}
+		}
+		// Closest first, and the longest waiting of those that are as close
+		slices.SortStableFunc(candidates, func(a, b Ticket) int {
+			return compareFloats(math.Abs(a.Rating-anchor.Rating), math.Abs(b.Rating-anchor.Rating))
+		})
+
+		group := append([]Ticket{anchor}, candidates[:min(len(candidates), q.Settings.MatchSize-1)]...)
+		if len(group) < q.Settings.MatchSize && (waited < q.Settings.MaxWait || len(group) < q.Settings.MinMatchSize) {
+			continue
+		}
+		for _, ticket := range group {
+			taken[ticket.Id] = true
+		}
+		groups = append(groups, group)
+	}
+
+	q.tickets = slices.DeleteFunc(q.tickets, func(ticket Ticket) bool {
+		return taken[ticket.Id]
+	})
+	return groups
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+package matchmaking_test
+
+import (
+	"server/internal/server/matchmaking"
+	"slices"
+	"testing"
+	"time"
+)
+
+var start = time.Unix(0, 0)
+
+func TestGapWidens(t *testing.T) {
+	settings := matchmaking.Settings{InitialGap: 100, GapGrowth: 10, MaxGap: 300}
+	tests := []struct {
+		waited time.Duration
+		want   float64
+	}{
+		{0, 100},
+		{5 * time.Second, 150},
+		{20 * time.Second, 300},
+		{time.Hour, 300},
+	}
+	for _, test := range tests {
+		if got := settings.Gap(test.waited); got != test.want {
+			t.Errorf("gap after %v is %v, want %v", test.waited, got, test.want)
+		}
+	}
+}
+
+// The ids in each group
+func ids(groups [][]matchmaking.Ticket) [][]uint64 {
+	var all [][]uint64
+	for _, group := range groups {
+		var groupIds []uint64
+		for _, ticket := range group {
+			groupIds = append(groupIds, ticket.Id)
+		}
+		all = append(all, groupIds)
+	}
+	return all
+}
+
+func expectGroups(t *testing.T, got [][]matchmaking.Ticket, want ...[]uint64) {
+	t.Helper()
+	if !slices.EqualFunc(ids(got), want, slices.Equal) {
+		t.Errorf("got groups %v, want %v", ids(got), want)
+	}
+}
+
+func TestMatchWaitsForTheGapToWiden(t *testing.T) {
+	queue := matchmaking.NewQueue(matchmaking.Settings{
+		MatchSize: 2, MinMatchSize: 2, MaxWait: time.Hour,
+		InitialGap: 100, GapGrowth: 10, MaxGap: 500,
+	})
+	queue.Add(matchmaking.Ticket{Id: 1, Rating: 1500, Joined: start})
+	queue.Add(matchmaking.Ticket{Id: 2, Rating: 1700, Joined: start})
+
+	// 200 apart, so they have to wait until the gap is 200
+	expectGroups(t, queue.Match(start.Add(9*time.Second)))
+	expectGroups(t, queue.Match(start.Add(10*time.Second)), []uint64{1, 2})
+	if queue.Len() != 0 {
+		t.Errorf("%d left in the queue after everyone was matched", queue.Len())
+	}
+}
+
+func TestMatchGroupsTheClosest(t *testing.T) {
+	queue := matchmaking.NewQueue(matchmaking.Settings{
+		MatchSize: 3, MinMatchSize: 2, MaxWait: time.Hour,
+		InitialGap: 300, GapGrowth: 0, MaxGap: 300,
+	})
+	ratings := []float64{1500, 1100, 1750, 1520, 1300, 1490, 1200}
+	for i, rating := range ratings {
+		queue.Add(matchmaking.Ticket{Id: uint64(i + 1), Rating: rating, Joined: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	// The longest waiting is matched first with the two closest to them, then
+	// the longest waiting of the rest. Nobody is close enough to the 1750 to
+	// make up a third.
+	expectGroups(t, queue.Match(start.Add(time.Minute)), []uint64{1, 6, 4}, []uint64{2, 7, 5})
+	left := queue.Tickets()
+	if len(left) != 1 || left[0].Id != 3 {
+		t.Errorf("left %v in the queue, want only 3", left)
+	}
+}
+
+func TestMatchStartsSmallAfterMaxWait(t *testing.T) {
+	queue := matchmaking.NewQueue(matchmaking.Settings{
+		MatchSize: 4, MinMatchSize: 2, MaxWait: 30 * time.Second,
+		InitialGap: 1000, GapGrowth: 0, MaxGap: 1000,
+	})
+	queue.Add(matchmaking.Ticket{Id: 1, Rating: 1500, Joined: start})
+	expectGroups(t, queue.Match(start.Add(time.Minute)))
+
+	queue.Add(matchmaking.Ticket{Id: 2, Rating: 1500, Joined: start.Add(50 * time.Second)})
+	expectGroups(t, queue.Match(start.Add(time.Minute)), []uint64{1, 2})
+}
+
+func TestAddKeepsJoinOrder(t *testing.T) {
+	queue := matchmaking.NewQueue(matchmaking.Settings{})
+	queue.Add(matchmaking.Ticket{Id: 1, Joined: start.Add(2 * time.Second)})
+	queue.Add(matchmaking.Ticket{Id: 2, Joined: start})
+	queue.Add(matchmaking.Ticket{Id: 3, Joined: start.Add(time.Second)})
+	if queue.Add(matchmaking.Ticket{Id: 1, Joined: start}) {
+		t.Error("added a ticket that was already queued")
+	}
+
+	var order []uint64
+	for _, ticket := range queue.Tickets() {
+		order = append(order, ticket.Id)
+	}
+	if want := []uint64{2, 3, 1}; !slices.Equal(order, want) {
+		t.Errorf("queued in order %v, want %v", order, want)
+	}
+
+	if !queue.Remove(3) || queue.Remove(3) {
+		t.Error("removing a ticket should work once")
+	}
+}
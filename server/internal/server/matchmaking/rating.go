@@ -0,0 +1,114 @@
+// Groups queued players into ranked matches of similar skill, and works out
+// their new ratings from how they placed. Knows nothing about rooms or
+// clients, so whole queues can be simulated without a server.
+package matchmaking
+
+import (
+	"math"
+	"sort"
+)
+
+// A Glicko rating: how good a player is thought to be, and how unsure we are
+// of that. New players start very unsure, so their first few matches move
+// their rating a long way.
+type Rating struct {
+	Rating    float64
+	Deviation float64
+}
+
+// How ratings start and how sure of them we can get
+type RatingSettings struct {
+	Initial          float64
+	InitialDeviation float64
+
+	// Deviations never drop below this, so ratings keep up with players who
+	// get better or worse
+	MinDeviation float64
+}
+
+func (s RatingSettings) New() Rating {
+	return Rating{Rating: s.Initial, Deviation: s.InitialDeviation}
+}
+
+// One player's part in a finished match. Place 1 is the winner, and players
+// can share a place.
+type Result struct {
+	Rating Rating
+	Place  int
+}
+
+// Glicko's q, which converts between the rating scale and natural logs
+var q = math.Ln10 / 400
+
+// Works out everyone's new rating, in the same order as results. Each match is
+// scored as if every pair of players had played each other: beating someone
+// means placing above them, and sharing a place is a draw.
+func (s RatingSettings) Update(results []Result) []Rating {
+	updated := make([]Rating, len(results))
+	for i, player := range results {
+		updated[i] = player.Rating
+		var sum, variance float64
+		for j, opponent := range results {
+			if i == j {
+				continue
+			}
+			g := impact(opponent.Rating.Deviation)
+			e := expected(player.Rating.Rating, opponent.Rating.Rating, g)
+			sum += g * (score(player.Place, opponent.Place) - e)
+			variance += g * g * e * (1 - e)
+		}
+		if variance == 0 {
+			// Nobody to compare with
+			continue
+		}
+
+		// 1/d² in Glicko's terms
+		information := q * q * variance
+		precision := 1/(player.Rating.Deviation*player.Rating.Deviation) + information
+		updated[i].Rating = player.Rating.Rating + q/precision*sum
+		updated[i].Deviation = math.Max(math.Sqrt(1/precision), s.MinDeviation)
+	}
+	return updated
+}
+
+// How much a result against an opponent counts, less the less sure we are of
+// their rating
+func impact(deviation float64) float64 {
+	return 1 / math.Sqrt(1+3*q*q*deviation*deviation/(math.Pi*math.Pi))
+}
+
+// The chance of a player rated a beating one rated b
+func expected(a, b, impact float64) float64 {
+	return 1 / (1 + math.Pow(10, -impact*(a-b)/400))
+}
+
+func score(place, opponentPlace int) float64 {
+	switch {
+	case place < opponentPlace:
+		return 1
+	case place > opponentPlace:
+		return 0
+	}
+	return 0.5
+}
+
+// Places players by score, highest first, with equal scores sharing a place
+func Places(scores []float64) []int {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+
+	places := make([]int, len(scores))
+	for rank, i := range order {
+		if rank > 0 && scores[i] == scores[order[rank-1]] {
+			places[i] = places[order[rank-1]]
+		} else {
+			places[i] = rank + 1
+		}
+	}
+	return places
+}
@@ -0,0 +1,72 @@
+package matchmaking_test
+
+import (
+	"math"
+	"server/internal/server/matchmaking"
+	"slices"
+	"testing"
+)
+
+var ratingSettings = matchmaking.RatingSettings{Initial: 1500, InitialDeviation: 350, MinDeviation: 30}
+
+// The worked example from Glickman's "The Glicko system": a player rated 1500
+// with a deviation of 200 beats a 1400 and loses to a 1550 and a 1700
+func TestUpdateMatchesGlickmansExample(t *testing.T) {
+	results := []matchmaking.Result{
+		{Rating: matchmaking.Rating{Rating: 1500, Deviation: 200}, Place: 3},
+		{Rating: matchmaking.Rating{Rating: 1400, Deviation: 30}, Place: 4},
+		{Rating: matchmaking.Rating{Rating: 1550, Deviation: 100}, Place: 2},
+		{Rating: matchmaking.Rating{Rating: 1700, Deviation: 300}, Place: 1},
+	}
+	got := ratingSettings.Update(results)[0]
+	if math.Abs(got.Rating-1464) > 0.5 || math.Abs(got.Deviation-151.4) > 0.05 {
+		t.Errorf("got %.2f ± %.2f, want 1464 ± 151.4", got.Rating, got.Deviation)
+	}
+}
+
+func TestUpdateBetweenEquals(t *testing.T) {
+	fresh := ratingSettings.New()
+
+	won := ratingSettings.Update([]matchmaking.Result{{Rating: fresh, Place: 1}, {Rating: fresh, Place: 2}})
+	if won[0].Rating <= fresh.Rating || won[1].Rating >= fresh.Rating {
+		t.Errorf("winner went to %.2f and loser to %.2f from %.2f", won[0].Rating, won[1].Rating, fresh.Rating)
+	}
+	if math.Abs(won[0].Rating-fresh.Rating-(fresh.Rating-won[1].Rating)) > 1e-9 {
+		t.Errorf("winner gained %.2f but loser lost %.2f", won[0].Rating-fresh.Rating, fresh.Rating-won[1].Rating)
+	}
+	for _, rating := range won {
+		if rating.Deviation >= fresh.Deviation {
+			t.Errorf("deviation went from %.2f to %.2f, want it to shrink", fresh.Deviation, rating.Deviation)
+		}
+	}
+
+	drawn := ratingSettings.Update([]matchmaking.Result{{Rating: fresh, Place: 1}, {Rating: fresh, Place: 1}})
+	for _, rating := range drawn {
+		if rating.Rating != fresh.Rating {
+			t.Errorf("a draw between equals moved a rating to %.2f", rating.Rating)
+		}
+	}
+}
+
+func TestUpdateKeepsMinDeviation(t *testing.T) {
+	sure := matchmaking.Rating{Rating: 1500, Deviation: ratingSettings.MinDeviation}
+	for _, rating := range ratingSettings.Update([]matchmaking.Result{{Rating: sure, Place: 1}, {Rating: sure, Place: 2}}) {
+		if rating.Deviation != ratingSettings.MinDeviation {
+			t.Errorf("deviation went to %.2f, want it to stay at %.2f", rating.Deviation, ratingSettings.MinDeviation)
+		}
+	}
+}
+
+func TestUpdateAlone(t *testing.T) {
+	fresh := ratingSettings.New()
+	if got := ratingSettings.Update([]matchmaking.Result{{Rating: fresh, Place: 1}}); got[0] != fresh {
+		t.Errorf("playing nobody changed the rating to %+v", got[0])
+	}
+}
+
+func TestPlaces(t *testing.T) {
+	got := matchmaking.Places([]float64{10, 30, 20, 30, 5})
+	if want := []int{4, 1, 3, 1, 5}; !slices.Equal(got, want) {
+		t.Errorf("got places %v, want %v", got, want)
+	}
+}
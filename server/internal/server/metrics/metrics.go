@@ -0,0 +1,358 @@
+// A small subset of Prometheus-style metrics, written out in the text
+// exposition format so any Prometheus-compatible scraper can read them.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Anything that can write itself out in the exposition format
+type Collector interface {
+	Write(w io.Writer) error
+}
+
+type Registry struct {
+	collectors []Collector
+	mux        sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) Register(collectors ...Collector) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.collectors = append(r.collectors, collectors...)
+}
+
+func (r *Registry) Write(w io.Writer) error {
+	r.mux.Lock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mux.Unlock()
+
+	for _, c := range collectors {
+		if err := c.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Serves the registry's metrics over HTTP
+func Handler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// A float64 that can be updated atomically
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		old := f.bits.Load()
+		new := math.Float64bits(math.Float64frombits(old) + delta)
+		if f.bits.CompareAndSwap(old, new) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) Set(value float64) {
+	f.bits.Store(math.Float64bits(value))
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+// A value that only goes up
+type Counter struct {
+	value atomicFloat
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counters can't go down")
+	}
+	c.value.Add(delta)
+}
+
+func (c *Counter) Value() float64 {
+	return c.value.Load()
+}
+
+// A value that can go up and down
+type Gauge struct {
+	value atomicFloat
+}
+
+func (g *Gauge) Inc() {
+	g.value.Add(1)
+}
+
+func (g *Gauge) Dec() {
+	g.value.Add(-1)
+}
+
+func (g *Gauge) Add(delta float64) {
+	g.value.Add(delta)
+}
+
+func (g *Gauge) Set(value float64) {
+	g.value.Set(value)
+}
+
+func (g *Gauge) Value() float64 {
+	return g.value.Load()
+}
+
+// Counts observations into cumulative buckets, like a Prometheus histogram
+type Histogram struct {
+	upperBounds []float64
+	counts      []atomic.Uint64 // One per upper bound, plus one for +Inf
+	sum         atomicFloat
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		upperBounds: buckets,
+		counts:      make([]atomic.Uint64, len(buckets)+1),
+	}
+}
+
+func (h *Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(h.upperBounds, value)
+	h.counts[i].Add(1)
+	h.sum.Add(value)
+}
+
+// Buckets starting at start, each factor times bigger than the last
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// A family of metrics of one type, told apart by their label values
+type vec[T any] struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	newMetric  func() *T
+	write      func(w io.Writer, name string, labels string, metric *T) error
+
+	metrics map[string]*T
+	mux     sync.Mutex
+}
+
+// Returns the metric with the given label values, creating it if needed
+func (v *vec[T]) With(labelValues ...string) *T {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: %s needs %d label values, got %d", v.name, len(v.labelNames), len(labelValues)))
+	}
+	key := formatLabels(v.labelNames, labelValues)
+
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	metric, exists := v.metrics[key]
+	if !exists {
+		metric = v.newMetric()
+		v.metrics[key] = metric
+	}
+	return metric
+}
+
+func (v *vec[T]) Write(w io.Writer) error {
+	v.mux.Lock()
+	keys := make([]string, 0, len(v.metrics))
+	for key := range v.metrics {
+		keys = append(keys, key)
+	}
+	v.mux.Unlock()
+	sort.Strings(keys)
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, escapeHelp(v.help), v.name, v.kind); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		v.mux.Lock()
+		metric := v.metrics[key]
+		v.mux.Unlock()
+		if err := v.write(w, v.name, key, metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type CounterVec struct {
+	vec[Counter]
+}
+
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{vec[Counter]{
+		name:       name,
+		help:       help,
+		kind:       "counter",
+		labelNames: labelNames,
+		newMetric:  func() *Counter { return &Counter{} },
+		write: func(w io.Writer, name string, labels string, c *Counter) error {
+			return writeSample(w, name, labels, c.Value())
+		},
+		metrics: make(map[string]*Counter),
+	}}
+}
+
+type GaugeVec struct {
+	vec[Gauge]
+}
+
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{vec[Gauge]{
+		name:       name,
+		help:       help,
+		kind:       "gauge",
+		labelNames: labelNames,
+		newMetric:  func() *Gauge { return &Gauge{} },
+		write: func(w io.Writer, name string, labels string, g *Gauge) error {
+			return writeSample(w, name, labels, g.Value())
+		},
+		metrics: make(map[string]*Gauge),
+	}}
+}
+
+type HistogramVec struct {
+	vec[Histogram]
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	return &HistogramVec{vec[Histogram]{
+		name:       name,
+		help:       help,
+		kind:       "histogram",
+		labelNames: labelNames,
+		newMetric:  func() *Histogram { return newHistogram(buckets) },
+		write:      writeHistogram,
+		metrics:    make(map[string]*Histogram),
+	}}
+}
+
+// Single metrics are vecs without labels
+func NewCounter(name, help string) (*Counter, Collector) {
+	v := NewCounterVec(name, help)
+	return v.With(), v
+}
+
+func NewGauge(name, help string) (*Gauge, Collector) {
+	v := NewGaugeVec(name, help)
+	return v.With(), v
+}
+
+func NewHistogram(name, help string, buckets []float64) (*Histogram, Collector) {
+	v := NewHistogramVec(name, help, buckets)
+	return v.With(), v
+}
+
+// A gauge whose value is read from a function whenever the metrics are written
+type GaugeFunc struct {
+	name  string
+	help  string
+	value func() float64
+}
+
+func NewGaugeFunc(name, help string, value func() float64) *GaugeFunc {
+	return &GaugeFunc{name: name, help: help, value: value}
+}
+
+func (g *GaugeFunc) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, escapeHelp(g.help), g.name); err != nil {
+		return err
+	}
+	return writeSample(w, g.name, "", g.value())
+}
+
+func writeHistogram(w io.Writer, name string, labels string, h *Histogram) error {
+	var cumulative uint64
+	for i := range h.counts {
+		cumulative += h.counts[i].Load()
+		le := "+Inf"
+		if i < len(h.upperBounds) {
+			le = formatFloat(h.upperBounds[i])
+		}
+		bucketLabels := joinLabels(labels, `le="`+le+`"`)
+		if err := writeSample(w, name+"_bucket", bucketLabels, float64(cumulative)); err != nil {
+			return err
+		}
+	}
+	if err := writeSample(w, name+"_sum", labels, h.sum.Load()); err != nil {
+		return err
+	}
+	return writeSample(w, name+"_count", labels, float64(cumulative))
+}
+
+func writeSample(w io.Writer, name string, labels string, value float64) error {
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	_, err := fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(value))
+	return err
+}
+
+func formatLabels(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i := range names {
+		pairs[i] = names[i] + `="` + escapeLabel(values[i]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func joinLabels(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "," + b
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
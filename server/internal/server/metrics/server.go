@@ -0,0 +1,64 @@
+package metrics
+
+// The registry served on /metrics
+var Default = NewRegistry()
+
+const namespace = "radius_rumble_"
+
+var (
+	// Clients by the name of the state they're in
+	ClientsByState = NewGaugeVec(namespace+"clients", "Connected clients by state", "state")
+
+	// Packets by the name of their message field, e.g. "player_direction"
+	PacketsIn  = NewCounterVec(namespace+"packets_in_total", "Packets received from clients by message type", "type")
+	PacketsOut = NewCounterVec(namespace+"packets_out_total", "Packets written to clients by message type", "type")
+
+	// Packets that were handed to a client but never written to its socket
+	DroppedSends = NewCounterVec(namespace+"dropped_sends_total", "Packets dropped before being sent, by reason", "reason")
+
+	SlowConsumerDisconnects, slowConsumerDisconnects = NewCounter(namespace+"slow_consumer_disconnects_total", "Clients disconnected because they couldn't keep up")
+
+	TickDuration = NewHistogramVec(
+		namespace+"tick_duration_seconds", "Time spent processing a single tick, by loop",
+		ExponentialBuckets(0.00001, 4, 10), "loop",
+	)
+
+	Rooms, rooms = NewGauge(namespace+"rooms", "Rooms open on the server")
+	Bots, bots   = NewGauge(namespace+"bots", "Bots the server is running to fill the rooms")
+
+	// Ranked matchmaking
+	QueueLength, queueLength = NewGauge(namespace+"queue_length", "Players waiting for a ranked match")
+	QueueWait, queueWait     = NewHistogram(namespace+"queue_wait_seconds", "Time players waited before their ranked match started", ExponentialBuckets(1, 2, 10))
+	Matches, matches         = NewCounter(namespace+"matches_total", "Ranked matches started")
+
+	Logins = NewCounterVec(namespace+"logins_total", "Login attempts by result", "result")
+
+	// Logins and registrations waiting for a worker to hash their password
+	AuthQueueLength, authQueueLength = NewGauge(namespace+"auth_queue_length", "Logins and registrations waiting for a worker")
+	AuthRejections, authRejections   = NewCounter(namespace+"auth_rejections_total", "Logins and registrations turned away because the auth queue was full")
+
+	DbQueryDuration = NewHistogramVec(
+		namespace+"db_query_duration_seconds", "Database query latency by query name",
+		ExponentialBuckets(0.0001, 4, 10), "query",
+	)
+)
+
+func init() {
+	Default.Register(
+		ClientsByState,
+		PacketsIn,
+		PacketsOut,
+		DroppedSends,
+		slowConsumerDisconnects,
+		TickDuration,
+		rooms,
+		bots,
+		queueLength,
+		queueWait,
+		matches,
+		Logins,
+		authQueueLength,
+		authRejections,
+		DbQueryDuration,
+	)
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"server/pkg/packets"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	ErrNoSuchClient = errors.New("no such client")
+	ErrNotLoggedIn  = errors.New("client is not logged in")
+)
+
+// Users who can't chat until the given time, keyed by user id so that
+// reconnecting doesn't lift the mute
+type mutes struct {
+	until map[int64]time.Time
+	mux   sync.Mutex
+}
+
+func (h *Hub) client(clientId uint64) (ClientInterface, error) {
+	client, exists := h.Clients.Get(clientId)
+	if !exists {
+		return nil, fmt.Errorf("%w: %d", ErrNoSuchClient, clientId)
+	}
+	return client, nil
+}
+
+// Disconnects the client, telling it why
+func (h *Hub) Kick(clientId uint64, reason string) error {
+	client, err := h.client(clientId)
+	if err != nil {
+		return err
+	}
+	h.Logger.Info("Kicking client", "client_id", clientId, "reason", reason)
+	disconnect(client, "Kicked: "+reason)
+	return nil
+}
+
+// Stops the user from logging in again, and kicks them if they're connected
+func (h *Hub) Ban(ctx context.Context, userId int64, reason string) error {
+	if err := h.store.BanUser(ctx, userId, reason); err != nil {
+		return err
+	}
+	h.Logger.Info("Banned user", "banned_user_id", userId, "reason", reason)
+
+	var banned []ClientInterface
+	h.Clients.ForEach(func(id uint64, client ClientInterface) {
+		if client.UserId() == userId {
+			banned = append(banned, client)
+		}
+	})
+	for _, client := range banned {
+		disconnect(client, "Banned: "+reason)
+	}
+	return nil
+}
+
+// Closes the client for something an admin did, so it's gone by the time they
+// hear back
+func disconnect(client ClientInterface, reason string) {
+	client.CloseWithCode(websocket.ClosePolicyViolation, reason)
+}
+
+func (h *Hub) Unban(ctx context.Context, userId int64) error {
+	h.Logger.Info("Unbanning user", "banned_user_id", userId)
+	return h.store.UnbanUser(ctx, userId)
+}
+
+// Stops the client's user from chatting for the given duration, or lifts the
+// mute if it's not positive
+func (h *Hub) Mute(clientId uint64, duration time.Duration) error {
+	client, err := h.client(clientId)
+	if err != nil {
+		return err
+	}
+	userId := client.UserId()
+	if userId == 0 {
+		return fmt.Errorf("%w: %d", ErrNotLoggedIn, clientId)
+	}
+
+	h.mutes.mux.Lock()
+	defer h.mutes.mux.Unlock()
+
+	if duration <= 0 {
+		delete(h.mutes.until, userId)
+		h.Logger.Info("Unmuted user", "muted_user_id", userId)
+		return nil
+	}
+	h.mutes.until[userId] = time.Now().Add(duration)
+	h.Logger.Info("Muted user", "muted_user_id", userId, "duration", duration)
+	client.SocketSendAs(0, packets.NewChat(fmt.Sprintf("You have been muted for %v", duration)))
+	return nil
+}
+
+func (h *Hub) Muted(userId int64) bool {
+	h.mutes.mux.Lock()
+	defer h.mutes.mux.Unlock()
+
+	until, exists := h.mutes.until[userId]
+	if exists && time.Now().After(until) {
+		delete(h.mutes.until, userId)
+		return false
+	}
+	return exists
+}
+
+// For database access that isn't on behalf of any one client
+func (h *Hub) AdminDbTx() *DbTx {
+	return h.NewDbTx(func() *slog.Logger { return h.Logger })
+}
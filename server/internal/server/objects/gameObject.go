@@ -1,5 +1,12 @@
 package objects
 
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A player is only ever changed by its owning client's goroutine. Everyone else
+// reads the copy it last published with Snapshot.
 type Player struct {
 	Name      string
 	X         float64
@@ -7,4 +14,60 @@ type Player struct {
 	Radius    float64
 	Direction float64
 	Speed     float64
+	Rtt       time.Duration
+	History   *PositionHistory
+
+	// Which life the player is on, counting each time it spawns
+	Life uint64
+
+	// Life*2, plus 1 once someone has claimed to have eaten the player in
+	// this life, so only the first claim on each life counts
+	claims atomic.Uint64
+
+	published atomic.Pointer[Player]
+}
+
+// Starts the player's next life, which can be eaten again. Only the owner may
+// call it, before publishing where it has respawned.
+func (p *Player) Respawn() {
+	p.Life++
+	p.claims.Store(p.Life * 2)
+}
+
+// Claims to have eaten the player in the given life, as seen in a snapshot.
+// Returns false if someone already has, or the player has moved on to another
+// life since, so two eaters can never both have it.
+func (p *Player) Claim(life uint64) bool {
+	return p.claims.CompareAndSwap(life*2, life*2+1)
+}
+
+// Makes the player's current state visible to Snapshot. Only the owner may
+// call it.
+func (p *Player) Publish() {
+	p.published.Store(&Player{
+		Name:      p.Name,
+		X:         p.X,
+		Y:         p.Y,
+		Radius:    p.Radius,
+		Direction: p.Direction,
+		Speed:     p.Speed,
+		Rtt:       p.Rtt,
+		History:   p.History,
+		Life:      p.Life,
+	})
+}
+
+// The player as of the last Publish, safe to read from any goroutine. Don't
+// change it.
+func (p *Player) Snapshot() *Player {
+	if snapshot := p.published.Load(); snapshot != nil {
+		return snapshot
+	}
+	return &Player{Name: p.Name, History: p.History}
+}
+
+type Spore struct {
+	X      float64
+	Y      float64
+	Radius float64
 }
@@ -0,0 +1,40 @@
+package objects
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOnlyOneClaimPerLife(t *testing.T) {
+	player := &Player{}
+	player.Respawn()
+	player.Publish()
+	life := player.Snapshot().Life
+
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if player.Claim(life) {
+				wins.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if wins.Load() != 1 {
+		t.Fatalf("%d claims on the same life won, want 1", wins.Load())
+	}
+
+	// Claims made against the last life don't count for the next one
+	player.Respawn()
+	player.Publish()
+	if player.Claim(life) {
+		t.Error("claim on a previous life won")
+	}
+	if !player.Claim(player.Snapshot().Life) {
+		t.Error("claim on the new life lost")
+	}
+}
@@ -0,0 +1,97 @@
+package objects
+
+import (
+	"sync"
+	"time"
+)
+
+// Where an entity was, and how big it was, at a point in time
+type PositionSample struct {
+	Time   time.Time
+	X      float64
+	Y      float64
+	Radius float64
+}
+
+// A thread-safe ring buffer of an entity's recent positions, so interactions
+// reported by a client can be checked against the world as the client saw it.
+type PositionHistory struct {
+	samples []PositionSample
+	next    int
+	count   int
+	mux     sync.Mutex
+}
+
+func NewPositionHistory(capacity int) *PositionHistory {
+	return &PositionHistory{
+		samples: make([]PositionSample, capacity),
+	}
+}
+
+// Records a new sample, overwriting the oldest one if the buffer is full.
+// Samples must be recorded in chronological order.
+func (h *PositionHistory) Record(sample PositionSample) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % len(h.samples)
+	if h.count < len(h.samples) {
+		h.count++
+	}
+}
+
+// Forgets all samples, e.g. when the entity respawns somewhere else
+func (h *PositionHistory) Clear() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.next = 0
+	h.count = 0
+}
+
+// Returns where the entity was at the given time, interpolating between the
+// two samples around it. Times before the oldest sample or after the newest
+// are clamped to those samples. Returns false if nothing has been recorded.
+func (h *PositionHistory) At(t time.Time) (PositionSample, bool) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.count == 0 {
+		return PositionSample{}, false
+	}
+
+	// Walk from the newest sample back in time until we pass t
+	newer := h.sample(0)
+	if !t.Before(newer.Time) {
+		return newer, true
+	}
+	for i := 1; i < h.count; i++ {
+		older := h.sample(i)
+		if !t.Before(older.Time) {
+			return interpolate(older, newer, t), true
+		}
+		newer = older
+	}
+	return newer, true
+}
+
+// The i-th newest sample, where 0 is the most recent one
+func (h *PositionHistory) sample(i int) PositionSample {
+	idx := (h.next - 1 - i + 2*len(h.samples)) % len(h.samples)
+	return h.samples[idx]
+}
+
+func interpolate(older, newer PositionSample, t time.Time) PositionSample {
+	span := newer.Time.Sub(older.Time)
+	if span <= 0 {
+		return newer
+	}
+	f := float64(t.Sub(older.Time)) / float64(span)
+	return PositionSample{
+		Time:   t,
+		X:      older.X + (newer.X-older.X)*f,
+		Y:      older.Y + (newer.Y-older.Y)*f,
+		Radius: older.Radius + (newer.Radius-older.Radius)*f,
+	}
+}
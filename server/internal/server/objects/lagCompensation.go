@@ -0,0 +1,49 @@
+package objects
+
+import (
+	"math"
+	"time"
+)
+
+// Works out which moment a client was looking at when it reported an
+// interaction, so the server can judge it against the same picture.
+type LagCompensator struct {
+	// Never rewind further than this, however laggy the client is
+	MaxRewind time.Duration
+
+	// How far behind the latest update clients render other entities
+	InterpolationDelay time.Duration
+}
+
+// The time the world was at on the screen of a client with the given RTT.
+// Updates reach the client half a round trip after we send them.
+func (l LagCompensator) ViewTime(now time.Time, rtt time.Duration) time.Time {
+	rewind := rtt/2 + l.InterpolationDelay
+	if rewind > l.MaxRewind {
+		rewind = l.MaxRewind
+	}
+	return now.Add(-rewind)
+}
+
+// Whether the eater, where it is now, could consume the target where the
+// eater's client saw it
+func (l LagCompensator) CheckConsume(now time.Time, rtt time.Duration, eater PositionSample, target *PositionHistory, eatRatio float64) bool {
+	seen, ok := target.At(l.ViewTime(now, rtt))
+	if !ok {
+		return false
+	}
+	return CanConsume(eater, seen, eatRatio)
+}
+
+// The eater has to be eatRatio times bigger than the target and cover its centre
+func CanConsume(eater, target PositionSample, eatRatio float64) bool {
+	if eater.Radius < target.Radius*eatRatio {
+		return false
+	}
+	return math.Hypot(eater.X-target.X, eater.Y-target.Y) < eater.Radius
+}
+
+// Whether the two circles touch
+func Overlaps(a, b PositionSample) bool {
+	return math.Hypot(a.X-b.X, a.Y-b.Y) < a.Radius+b.Radius
+}
@@ -0,0 +1,124 @@
+package objects
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+var compensator = LagCompensator{
+	MaxRewind:          250 * time.Millisecond,
+	InterpolationDelay: 100 * time.Millisecond,
+}
+
+// A target moving right at 100 units a second from the origin, recorded every
+// 50ms tick for two seconds, which is when it's judged
+func movingTarget(start time.Time) (*PositionHistory, time.Time) {
+	history := NewPositionHistory(64)
+	var now time.Time
+	for tick := 0; tick <= 40; tick++ {
+		now = start.Add(time.Duration(tick) * 50 * time.Millisecond)
+		history.Record(PositionSample{Time: now, X: 100 * now.Sub(start).Seconds(), Radius: 10})
+	}
+	return history, now
+}
+
+func TestViewTime(t *testing.T) {
+	now := time.Unix(100, 0)
+	tests := []struct {
+		rtt  time.Duration
+		want time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{80 * time.Millisecond, 140 * time.Millisecond},
+		{300 * time.Millisecond, 250 * time.Millisecond},
+		{2 * time.Second, 250 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if got := now.Sub(compensator.ViewTime(now, test.rtt)); got != test.want {
+			t.Errorf("rtt %v rewinds %v, want %v", test.rtt, got, test.want)
+		}
+	}
+}
+
+// Whatever their latency, a player who overlapped the target on their own
+// screen gets it, and one who didn't doesn't
+func TestCheckConsumeIsFairAtDifferentLatencies(t *testing.T) {
+	start := time.Unix(100, 0)
+	history, now := movingTarget(start)
+
+	for _, rtt := range []time.Duration{0, 30 * time.Millisecond, 80 * time.Millisecond, 150 * time.Millisecond, 300 * time.Millisecond} {
+		t.Run(fmt.Sprint(rtt), func(t *testing.T) {
+			seen, _ := history.At(compensator.ViewTime(now, rtt))
+
+			// Just covering where the target was on their screen, which is too far
+			// behind where it is now the more lag there is
+			hit := PositionSample{Time: now, X: seen.X - 25, Radius: 30}
+			if !compensator.CheckConsume(now, rtt, hit, history, 1.2) {
+				t.Errorf("claim covering the target at x=%v was rejected", seen.X)
+			}
+
+			miss := PositionSample{Time: now, X: seen.X - 35, Radius: 30}
+			if compensator.CheckConsume(now, rtt, miss, history, 1.2) {
+				t.Errorf("claim missing the target at x=%v was accepted", seen.X)
+			}
+		})
+	}
+}
+
+// However laggy the client, it can't eat a target where it was longer ago than
+// MaxRewind
+func TestCheckConsumeRewindsNoFurtherThanMax(t *testing.T) {
+	start := time.Unix(100, 0)
+	history, now := movingTarget(start)
+
+	// On a 600ms round trip the target was at 160 on the client's screen, but
+	// only rewinding 250ms it's judged at 175
+	eater := PositionSample{Time: now, X: 150, Radius: 20}
+	if compensator.CheckConsume(now, 600*time.Millisecond, eater, history, 1.2) {
+		t.Error("claim against a view older than MaxRewind was accepted")
+	}
+	eater.X = 175
+	if !compensator.CheckConsume(now, 600*time.Millisecond, eater, history, 1.2) {
+		t.Error("claim against the view MaxRewind ago was rejected")
+	}
+}
+
+func TestCheckConsumeNeedsEatRatio(t *testing.T) {
+	history, now := movingTarget(time.Unix(100, 0))
+	seen, _ := history.At(compensator.ViewTime(now, 0))
+
+	eater := PositionSample{Time: now, X: seen.X, Radius: 11}
+	if compensator.CheckConsume(now, 0, eater, history, 1.2) {
+		t.Error("claim by an eater not big enough was accepted")
+	}
+}
+
+func TestHistoryAt(t *testing.T) {
+	start := time.Unix(100, 0)
+	history := NewPositionHistory(4)
+	if _, ok := history.At(start); ok {
+		t.Fatal("empty history has a position")
+	}
+	for i := 0; i < 6; i++ {
+		history.Record(PositionSample{Time: start.Add(time.Duration(i) * time.Second), X: float64(i * 10)})
+	}
+
+	tests := []struct {
+		at   time.Duration
+		want float64
+	}{
+		{5 * time.Second, 50},
+		{10 * time.Second, 50},        // After the newest sample
+		{4500 * time.Millisecond, 45}, // Between two samples
+		{2 * time.Second, 20},         // The oldest sample that's still kept
+		{0, 20},                       // Overwritten, so clamped to the oldest
+		{2250 * time.Millisecond, 22.5},
+	}
+	for _, test := range tests {
+		got, _ := history.At(start.Add(test.at))
+		if got.X != test.want {
+			t.Errorf("at %v got x=%v, want %v", test.at, got.X, test.want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package objects
 
-import "sync"
+import (
+	"slices"
+	"sync"
+)
 
 // A generic, thread-safe map of objects with auto-incrementing IDs.
 type SharedCollection[T any] struct {
@@ -50,6 +53,17 @@ func (c *SharedCollection[T]) Remove(id uint64) {
 
 	delete(c.objectsMap, id)
 }
+
+// Removes the object and returns it, or returns false if it was already gone
+func (c *SharedCollection[T]) Take(id uint64) (T, bool) {
+	c.mapMux.Lock()
+	defer c.mapMux.Unlock()
+
+	obj, exists := c.objectsMap[id]
+	delete(c.objectsMap, id)
+	return obj, exists
+}
+
 func (s *SharedCollection[T]) ForEach(callback func(id uint64, obj T)) {
 	s.mapMux.Lock()
 	localCopy := make(map[uint64]T, len(s.objectsMap))
@@ -73,6 +87,19 @@ func (c *SharedCollection[T]) GetAll() []T {
 	return allObjects
 }
 
+// All the ids in ascending order, for when the order things are visited in matters
+func (c *SharedCollection[T]) Ids() []uint64 {
+	c.mapMux.Lock()
+	ids := make([]uint64, 0, len(c.objectsMap))
+	for id := range c.objectsMap {
+		ids = append(ids, id)
+	}
+	c.mapMux.Unlock()
+
+	slices.Sort(ids)
+	return ids
+}
+
 func (c *SharedCollection[T]) Len() int {
 	c.mapMux.Lock()
 	defer c.mapMux.Unlock()
@@ -0,0 +1,16 @@
+package server
+
+import "math/rand"
+
+// Every random choice the simulation makes comes from streams derived from one
+// seed. Each player gets its own stream, so the numbers it sees don't depend on
+// what order players happen to be handled in.
+type Randomness struct {
+	Seed int64
+}
+
+// Stream 0 is the world's, the rest belong to the client with that id
+func (r Randomness) Stream(id uint64) *rand.Rand {
+	// Spread neighbouring ids out so their streams aren't related
+	return rand.New(rand.NewSource(r.Seed ^ int64(id*0x9e3779b97f4a7c15)))
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"server/internal/server/config"
+	"server/pkg/packets"
+	"server/pkg/replay"
+	"time"
+)
+
+// Writes everything the world broadcasts to a replay file, see cmd/replay
+type recorder struct {
+	file   *os.File
+	writer *replay.Writer
+}
+
+func newRecorder(path string, header replay.Header) (*recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := replay.NewWriter(file, header)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("writing header: %w", err)
+	}
+	return &recorder{file: file, writer: writer}, nil
+}
+
+func (r *recorder) record(tick uint64, packet *packets.Packet) error {
+	return r.writer.Write(tick, packet)
+}
+
+// Playback has to keep pace with the ticks as they were, so changes to how long
+// they are go in the replay too
+func (r *recorder) recordTuning(tick uint64, tuning *config.GameConfig) error {
+	return r.writer.WriteTuning(tick, replay.Tuning{
+		TickInterval: tuning.TickInterval.Duration,
+		MapSize:      tuning.MapSize,
+	})
+}
+
+func (r *recorder) close() error {
+	if err := r.writer.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// Starts recording the room's broadcasts to a new file in the replay directory,
+// if there is one. Recording problems are logged and stop the recording, never
+// the game.
+func (r *Room) startRecording() {
+	dir := r.hub.Config.Server.ReplayDir
+	if dir == "" {
+		return
+	}
+
+	startedAt := time.Now().UTC()
+	path := filepath.Join(dir, fmt.Sprintf("room%d-%s.replay", r.Id, startedAt.Format("20060102-150405")))
+	recorder, err := newRecorder(path, replay.Header{
+		Seed:         r.World.Random.Seed,
+		TickInterval: r.Tuning().TickInterval.Duration,
+		MapSize:      r.Tuning().MapSize,
+		StartedAt:    startedAt,
+	})
+	if err != nil {
+		r.logger.Error("Failed to start recording a replay", "path", path, "error", err)
+		return
+	}
+	r.logger.Info("Recording a replay", "path", path)
+	r.recorder = recorder
+}
+
+func (r *Room) record(packet *packets.Packet) {
+	if r.recorder == nil {
+		return
+	}
+	r.checkRecording(r.recorder.record(r.ticks.Load(), packet))
+}
+
+// A change of tuning, and the tick it applies from
+type tuningChange struct {
+	tick   uint64
+	tuning *config.GameConfig
+}
+
+// Has the broadcast loop record the tuning the room switched to, in order with
+// the broadcasts. Dropped once the room has closed.
+func (r *Room) tuningChanged(tick uint64, tuning *config.GameConfig) {
+	select {
+	case r.tuningChanges <- tuningChange{tick: tick, tuning: tuning}:
+	case <-r.quit:
+	case <-r.hub.quit:
+	}
+}
+
+func (r *Room) recordTuning(change tuningChange) {
+	if r.recorder == nil {
+		return
+	}
+	r.checkRecording(r.recorder.recordTuning(change.tick, change.tuning))
+}
+
+func (r *Room) checkRecording(err error) {
+	if err != nil {
+		r.logger.Error("Failed to record a replay, stopping the recording", "error", err)
+		r.stopRecording()
+	}
+}
+
+func (r *Room) stopRecording() {
+	if r.recorder == nil {
+		return
+	}
+	if err := r.recorder.close(); err != nil {
+		r.logger.Error("Failed to finish the replay", "error", err)
+	}
+	r.recorder = nil
+}
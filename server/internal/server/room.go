@@ -0,0 +1,163 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"server/internal/server/config"
+	"server/internal/server/objects"
+	"server/pkg/packets"
+	"sync/atomic"
+	"time"
+)
+
+// A world of its own: its players and spores, its tuning, the clients playing
+// or watching in it, and the loops that tick it and pass its broadcasts around.
+// Clients come and go through Rooms.
+type Room struct {
+	Id    uint64
+	Name  string
+	World *SharedGameObjects
+
+	// Everyone in the room, whether they're playing or watching
+	Clients *objects.SharedCollection[ClientInterface]
+
+	// Players the room takes before new ones are sent elsewhere
+	MaxPlayers int
+
+	// The ranked match being played in the room, nil for a casual room
+	match *Match
+
+	hub           *Hub
+	logger        *slog.Logger
+	broadcastChan chan *packets.Packet
+
+	// Tuning the game loop has switched to, for the broadcast loop to record
+	tuningChanges chan tuningChange
+
+	// The tuning in effect, and a reloaded one waiting for the next tick
+	tuning        atomic.Pointer[config.GameConfig]
+	pendingTuning atomic.Pointer[config.GameConfig]
+
+	// The room's own random numbers, only used by the game loop
+	rng *rand.Rand
+
+	// Ticks run so far, and where broadcasts are being recorded to (only
+	// touched by the broadcast loop, nil if they aren't)
+	ticks    atomic.Uint64
+	recorder *recorder
+
+	// Guarded by the Rooms' lock. The first room is permanent, the others
+	// close once they've been empty for a while.
+	permanent  bool
+	players    int
+	emptySince time.Time
+	closed     bool
+
+	quit    chan struct{}
+	stopped chan struct{}
+}
+
+func newRoom(hub *Hub, id uint64, permanent bool, match *Match) *Room {
+	r := &Room{
+		Id:   id,
+		Name: fmt.Sprintf("Room %d", id),
+		World: &SharedGameObjects{
+			Players: objects.NewSharedCollection[*objects.Player](),
+			Spores:  objects.NewSharedCollection[*objects.Spore](),
+			Clock:   hub.Clock,
+			// Every room plays out differently, but the same every time
+			Random: Randomness{Seed: hub.Random.Seed + int64(id)},
+		},
+		Clients:       objects.NewSharedCollection[ClientInterface](),
+		MaxPlayers:    hub.Config.Rooms.MaxPlayers,
+		match:         match,
+		hub:           hub,
+		logger:        hub.Logger.With("room", id),
+		broadcastChan: make(chan *packets.Packet, hub.Config.Network.HubChannelSize),
+		tuningChanges: make(chan tuningChange, 1),
+		permanent:     permanent,
+		emptySince:    hub.Clock.Now(),
+		quit:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	if match != nil {
+		r.Name = fmt.Sprintf("Ranked match %d", id)
+		r.MaxPlayers = len(match.Players)
+	}
+	r.rng = r.World.Random.Stream(0)
+	r.tuning.Store(hub.Tuning())
+	return r
+}
+
+// Starts the room's loops, which run until the room closes or the hub stops
+func (r *Room) run() {
+	r.startRecording()
+	go r.runGameLoop()
+	go r.runBroadcasts()
+}
+
+// Passes each broadcast to everyone in the room but its sender, recording them
+// and any change of tuning in order
+func (r *Room) runBroadcasts() {
+	defer close(r.stopped)
+	defer r.stopRecording()
+
+	for {
+		select {
+		case packet := <-r.broadcastChan:
+			r.record(packet)
+			r.Clients.ForEach(func(id uint64, client ClientInterface) {
+				if id != packet.SenderId {
+					client.ProcessMessage(packet.SenderId, packet.Msg)
+				}
+			})
+		case change := <-r.tuningChanges:
+			r.recordTuning(change)
+		case <-r.quit:
+			return
+		case <-r.hub.quit:
+			return
+		}
+	}
+}
+
+// Sends the packet to everyone in the room but its sender. Dropped once the
+// room has closed.
+func (r *Room) Broadcast(packet *packets.Packet) {
+	select {
+	case r.broadcastChan <- packet:
+	case <-r.quit:
+	case <-r.hub.quit:
+	}
+}
+
+// What clients are told about the room
+func (r *Room) Info() *packets.RoomInfo {
+	return &packets.RoomInfo{
+		Id:         r.Id,
+		Name:       r.Name,
+		Players:    uint32(r.World.Players.Len()),
+		MaxPlayers: uint32(r.MaxPlayers),
+		Ranked:     r.Ranked(),
+	}
+}
+
+// Whether the room is for a ranked match, rather than anyone
+func (r *Room) Ranked() bool {
+	return r.match != nil
+}
+
+// The room's ranked match, nil for a casual room
+func (r *Room) Match() *Match {
+	return r.match
+}
+
+// Whether the client could play in the room: it's casual and there's space,
+// or it's a ranked match the client is part of that isn't over yet
+func (r *Room) Admits(client ClientInterface) bool {
+	if r.match != nil {
+		return r.match.includes(client.Id()) && !r.match.Over()
+	}
+	return r.World.Players.Len() < r.MaxPlayers
+}
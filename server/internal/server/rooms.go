@@ -0,0 +1,183 @@
+package server
+
+import (
+	"cmp"
+	"maps"
+	"server/internal/server/metrics"
+	"slices"
+	"sync"
+)
+
+// All the open rooms, and who's in which. Clients are put in a room when their
+// state enters it and taken out when it exits.
+type Rooms struct {
+	hub    *Hub
+	mux    sync.Mutex
+	rooms  map[uint64]*Room
+	nextId uint64
+}
+
+func newRooms(hub *Hub) *Rooms {
+	return &Rooms{hub: hub, rooms: make(map[uint64]*Room), nextId: 1}
+}
+
+func (rs *Rooms) Get(id uint64) (*Room, bool) {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+
+	room, exists := rs.rooms[id]
+	return room, exists
+}
+
+// The open rooms in the order they were opened
+func (rs *Rooms) List() []*Room {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+
+	return rs.sorted()
+}
+
+func (rs *Rooms) sorted() []*Room {
+	rooms := slices.Collect(maps.Values(rs.rooms))
+	slices.SortFunc(rooms, func(a, b *Room) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	return rooms
+}
+
+// Opens a new room and starts it running. The first one is there for good,
+// the rest close once they've been empty for long enough.
+func (rs *Rooms) Open() *Room {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+
+	return rs.create(nil)
+}
+
+// Opens a new room for the ranked match, which only its players can play in
+func (rs *Rooms) OpenMatch(match *Match) *Room {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+
+	return rs.create(match)
+}
+
+func (rs *Rooms) create(match *Match) *Room {
+	room := newRoom(rs.hub, rs.nextId, rs.nextId == 1, match)
+	rs.nextId++
+	rs.rooms[room.Id] = room
+	room.run()
+	room.logger.Info("Opened a room")
+	metrics.Rooms.Set(float64(len(rs.rooms)))
+	return room
+}
+
+// Puts the client in the room, as a player or a spectator, and returns the
+// room. A nil room means any casual one. Players go somewhere else if the room
+// is full or a ranked match they aren't in, and anyone does if it has closed:
+// players to the first casual room with space (opening a new one if they're
+// all full), spectators to the busiest one.
+func (rs *Rooms) Join(room *Room, client ClientInterface, playing bool) *Room {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+
+	if room != nil && (room.closed || playing && (room.players >= room.MaxPlayers || !room.Admits(client))) {
+		client.Logger().Info("Can't join the room, finding another", "room", room.Id, "closed", room.closed)
+		room = nil
+	}
+	if room == nil && playing {
+		room = rs.roomWithSpace()
+	}
+	if room == nil {
+		room = rs.busiest()
+	}
+
+	if playing {
+		room.players++
+	}
+	room.Clients.Add(client, client.Id())
+	client.SetRoom(room)
+	return room
+}
+
+func (rs *Rooms) roomWithSpace() *Room {
+	rooms := rs.casual()
+	for _, room := range rooms {
+		if room.players < room.MaxPlayers {
+			return room
+		}
+	}
+	if len(rs.rooms) < rs.hub.Config.Rooms.MaxRooms {
+		return rs.create(nil)
+	}
+
+	// Squeeze them in wherever there's the least crowd
+	return slices.MinFunc(rooms, func(a, b *Room) int {
+		return a.players - b.players
+	})
+}
+
+// The casual room with the most players, or the oldest if it's a tie
+func (rs *Rooms) busiest() *Room {
+	var busiest *Room
+	for _, room := range rs.casual() {
+		if busiest == nil || room.players > busiest.players {
+			busiest = room
+		}
+	}
+	return busiest
+}
+
+// The open rooms that aren't for ranked matches, which always includes the
+// first one
+func (rs *Rooms) casual() []*Room {
+	return slices.DeleteFunc(rs.sorted(), (*Room).Ranked)
+}
+
+// Takes the client out of the room it joined with Join
+func (rs *Rooms) Leave(room *Room, client ClientInterface, playing bool) {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+
+	if playing {
+		room.players--
+	}
+	room.Clients.Remove(client.Id())
+	if client.Room() == room {
+		client.SetRoom(nil)
+	}
+	if room.Clients.Len() == 0 {
+		room.emptySince = rs.hub.Clock.Now()
+	}
+}
+
+// Closes the room if it isn't the permanent one and nobody has been in it for
+// the configured timeout
+func (rs *Rooms) closeIfEmpty(room *Room) {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+
+	if room.permanent || room.closed || room.Clients.Len() > 0 {
+		return
+	}
+	if rs.hub.Clock.Now().Sub(room.emptySince) < rs.hub.Config.Rooms.EmptyTimeout.Duration {
+		return
+	}
+
+	room.closed = true
+	delete(rs.rooms, room.Id)
+	close(room.quit)
+	room.logger.Info("Closed an empty room")
+	metrics.Rooms.Set(float64(len(rs.rooms)))
+}
+
+// Waits for every room to have stopped, once the hub has
+func (rs *Rooms) wait() {
+	rs.mux.Lock()
+	rooms := rs.sorted()
+	rs.mux.Unlock()
+
+	for _, room := range rooms {
+		<-room.stopped
+	}
+}
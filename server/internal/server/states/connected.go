@@ -4,10 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"server/internal/server"
-	"server/internal/server/db"
-	"server/internal/server/objects"
+	"server/internal/server/metrics"
+	"server/internal/server/store"
 	"server/pkg/packets"
 	"strings"
 
@@ -15,22 +15,27 @@ import (
 )
 
 type Connected struct {
-	client  server.ClientInterface
-	logger  *log.Logger
-	queries *db.Queries
-	dbCtx   context.Context
+	client server.StateClient
+	logger *slog.Logger
+	dbTx   *server.DbTx
+
+	// Set while a login or registration is queued or running, with the one
+	// the client sent after it
+	authPending bool
+	nextAuth    func()
+
+	// Set once the client has moved on, so a result still on its way is dropped
+	exited bool
 }
 
 func (c *Connected) Name() string {
 	return "Connected"
 }
 
-func (c *Connected) SetClient(client server.ClientInterface) {
+func (c *Connected) SetClient(client server.StateClient) {
 	c.client = client
-	var loggingPrefix = fmt.Sprintf("[%s] ", c.Name())
-	c.logger = log.New(log.Writer(), loggingPrefix, log.LstdFlags)
-	c.queries = client.DbTx().Queries
-	c.dbCtx = client.DbTx().Ctx
+	c.logger = client.Logger()
+	c.dbTx = client.DbTx()
 }
 
 func (c *Connected) OnEnter() {
@@ -43,90 +48,212 @@ func (c *Connected) HandleMessage(senderId uint64, msg packets.Msg) {
 		c.handleLoginRequest(senderId, msg)
 	case *packets.Packet_RegisterRequest:
 		c.handleRegisterRequest(senderId, msg)
+	case *packets.Packet_Spectate:
+		c.handleSpectate(senderId, msg)
+	case *packets.Packet_RoomListRequest:
+		if senderId == c.client.Id() {
+			sendRoomList(c.client)
+		}
+	case *packets.Packet_JoinRoom:
+		c.handleJoinRoom(senderId, msg)
+	case *packets.Packet_JoinQueue:
+		if senderId == c.client.Id() && !c.busy() {
+			joinQueue(c.client, 0, "")
+		}
 	}
 }
 func (c *Connected) OnExit() {
+	c.exited = true
+	c.nextAuth = nil
+}
+
+// Visitors can watch without logging in
+func (c *Connected) handleSpectate(senderId uint64, message *packets.Packet_Spectate) {
+	if senderId == c.client.Id() && !c.busy() {
+		c.client.SetState(NewSpectating(nil, 0, "", message.Spectate))
+	}
+}
+
+// Visitors can't play, but they can watch whichever room they like
+func (c *Connected) handleJoinRoom(senderId uint64, message *packets.Packet_JoinRoom) {
+	if senderId != c.client.Id() || c.busy() {
+		return
+	}
+	if room, ok := acceptJoin(c.client, message, false); ok {
+		c.client.SetState(NewSpectating(room, 0, "", &packets.SpectateMessage{}))
+	}
 }
+
 func (c *Connected) handleLoginRequest(senderId uint64, message *packets.Packet_LoginRequest) {
 	if senderId != c.client.Id() {
-		c.logger.Printf("Received login message from another client (Id %d)", senderId)
+		c.logger.Warn("Received login message from another client", "sender_id", senderId)
 		return
 	}
 
 	username := message.LoginRequest.Username
+	password := message.LoginRequest.Password
+	c.submitAuth(func() {
+		user, denial := c.checkLogin(username, password)
+		c.client.Deliver(func() {
+			if c.exited {
+				return
+			}
+			defer c.finishAuth()
+			if denial != nil {
+				metrics.Logins.With("failure").Inc()
+				c.client.SocketSend(denial)
+				return
+			}
 
+			c.client.SetUserId(user.Id)
+			c.client.Logger().Info("User logged in", "username", username)
+			metrics.Logins.With("success").Inc()
+			c.client.SocketSend(packets.NewOkResponse())
+			c.client.SetState(NewIngame(nil, user.Id, username))
+		})
+	})
+}
+
+// Looks up the user and checks their password and ban, returning the message
+// to deny the login with if any of that fails. Runs on the auth pool.
+func (c *Connected) checkLogin(username, password string) (store.User, packets.Msg) {
 	genericFailMessage := packets.NewDenyResponse("Incorrect username or password")
 
-	user, err := c.queries.GetUserByUsername(c.dbCtx, strings.ToLower(username))
+	ctx, cancel := c.dbTx.OpContext()
+	user, err := c.dbTx.Users.GetUserByUsername(ctx, strings.ToLower(username))
+	cancel()
 	if err != nil {
-		c.logger.Printf("Error getting user %s: %v", username, err)
-		c.client.SocketSend(genericFailMessage)
-		return
+		c.logger.Info("Login failed, error getting user", "username", username, "error", err)
+		return user, genericFailMessage
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(message.LoginRequest.Password))
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		c.logger.Printf("User entered wrong password: %s", username)
-		c.client.SocketSend(genericFailMessage)
-		return
+		c.logger.Info("Login failed, wrong password", "username", username)
+		return user, genericFailMessage
 	}
 
-	c.logger.Printf("User %s logged in successfully", username)
-	c.client.SocketSend(packets.NewOkResponse())
-	c.client.SetState(&Ingame{
-		player: &objects.Player{
-			Name: username,
-		},
-	})
+	ctx, cancel = c.dbTx.OpContext()
+	ban, err := c.dbTx.Users.GetBan(ctx, user.Id)
+	cancel()
+	if err == nil {
+		c.logger.Info("Login failed, user is banned", "username", username)
+		return user, packets.NewDenyResponse("You are banned: " + ban.Reason)
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		c.logger.Error("Failed to check for a ban", "username", username, "error", err)
+		return user, genericFailMessage
+	}
+	return user, nil
 }
+
 func (c *Connected) handleRegisterRequest(senderId uint64, message *packets.Packet_RegisterRequest) {
 	if senderId != c.client.Id() {
-		c.logger.Printf("Received register message from another client (Id %d)", senderId)
+		c.logger.Warn("Received register message from another client", "sender_id", senderId)
 		return
 	}
 
 	username := strings.ToLower(message.RegisterRequest.Username)
-	err := validateUsername(message.RegisterRequest.Username)
+	err := ValidateUsername(message.RegisterRequest.Username)
 	if err != nil {
 		reason := fmt.Sprintf("Invalid username: %v", err)
-		c.logger.Println(reason)
+		c.logger.Info("Registration failed", "reason", reason)
 		c.client.SocketSend(packets.NewDenyResponse(reason))
 		return
 	}
 
-	_, err = c.queries.GetUserByUsername(c.dbCtx, username)
-	if err == nil {
-		c.logger.Printf("User already exists: %s", username)
-		c.client.SocketSend(packets.NewDenyResponse("User already exists"))
-		return
-	}
+	password := message.RegisterRequest.Password
+	c.submitAuth(func() {
+		response := c.register(username, password)
+		c.client.Deliver(func() {
+			if c.exited {
+				return
+			}
+			defer c.finishAuth()
+			c.client.SocketSend(response)
+		})
+	})
+}
 
+// Creates the user, returning the response to send. Runs on the auth pool.
+func (c *Connected) register(username, password string) packets.Msg {
 	genericFailMessage := packets.NewDenyResponse("Error registering user (internal server error) - please try again later")
 
-	// Add new user
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(message.RegisterRequest.Password), bcrypt.DefaultCost)
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		c.logger.Printf("Failed to hash password: %s", username)
-		c.client.SocketSend(genericFailMessage)
-		return
+		c.logger.Error("Failed to hash password", "username", username, "error", err)
+		return genericFailMessage
 	}
 
-	_, err = c.queries.CreateUser(c.dbCtx, db.CreateUserParams{
-		Username:     username,
-		PasswordHash: string(passwordHash),
+	// Checking for the name in the same transaction as creating the user, so
+	// nobody can take it in between
+	err = c.dbTx.InTx(func(ctx context.Context, tx store.Tx) error {
+		_, err := tx.GetUserByUsername(ctx, username)
+		if err == nil {
+			return store.ErrUserExists
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+		_, err = tx.CreateUser(ctx, username, string(passwordHash))
+		return err
 	})
 
+	if errors.Is(err, store.ErrUserExists) {
+		c.logger.Info("Registration failed, user already exists", "username", username)
+		return packets.NewDenyResponse("User already exists")
+	}
 	if err != nil {
-		c.logger.Printf("Failed to create user %s: %v", username, err)
-		c.client.SocketSend(genericFailMessage)
+		c.logger.Error("Failed to create user", "username", username, "error", err)
+		return genericFailMessage
+	}
+
+	c.logger.Info("User registered", "username", username)
+	return packets.NewOkResponse()
+}
+
+// Queues a login or registration, which hashes passwords and so is too slow to
+// do while reading packets. A client only gets one at a time, but can send the
+// next one (e.g. logging in right after registering) without waiting.
+func (c *Connected) submitAuth(job func()) {
+	if c.authPending {
+		if c.nextAuth != nil {
+			c.client.SocketSend(packets.NewDenyResponse("Still working on your last request"))
+			return
+		}
+		c.nextAuth = job
 		return
 	}
 
-	c.client.SocketSend(packets.NewOkResponse())
+	c.authPending = true
+	if !c.client.SubmitAuth(job) {
+		c.authPending = false
+		c.logger.Warn("Auth queue is full, turning the request away")
+		c.client.SocketSend(packets.NewDenyResponse("Server is busy, please try again in a moment"))
+	}
+}
 
-	c.logger.Printf("User %s registered successfully", username)
+// Leaving while a login is on its way would have it land in the wrong state,
+// so that has to wait. Says no and returns true if it does.
+func (c *Connected) busy() bool {
+	if c.authPending {
+		c.client.SocketSend(packets.NewDenyResponse("Still working on your last request"))
+	}
+	return c.authPending
 }
-func validateUsername(username string) error {
+
+// Called once a result has been handled, submitting the request that was
+// waiting on it if we're still in this state
+func (c *Connected) finishAuth() {
+	c.authPending = false
+	if next := c.nextAuth; next != nil {
+		c.nextAuth = nil
+		c.submitAuth(next)
+	}
+}
+
+// Checks a username as typed by the user, before it's lowercased
+func ValidateUsername(username string) error {
 	if len(username) <= 0 {
 		return errors.New("empty")
 	}
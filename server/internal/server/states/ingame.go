@@ -1,110 +1,387 @@
 package states
 
 import (
-	"context"
-	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"math/rand"
 	"server/internal/server"
+	"server/internal/server/config"
+	"server/internal/server/metrics"
 	"server/internal/server/objects"
+	"server/internal/server/store"
 	"server/pkg/packets"
 	"time"
 )
 
 type Ingame struct {
-	client                 server.ClientInterface
-	player                 *objects.Player
-	logger                 *log.Logger
-	cancelPlayerUpdateLoop context.CancelFunc
+	client server.StateClient
+	room   *server.Room
+	userId int64
+	player *objects.Player
+	logger *slog.Logger
+	rng    *rand.Rand
+
+	// Players stand still until their client first says where to go
+	moving bool
+
+	// The newest input from our client that has been applied to the player
+	lastInputSequence  uint32
+	lastInputTimestamp int64
+
+	// Stats for this session, saved when the state exits
+	bestScore    int64
+	playersEaten int64
+}
+
+// Puts a player with the given name into the room, or into any room with space
+// if it's nil. Clients that don't log in (like bots) use a zero user id, and
+// have no stats saved.
+func NewIngame(room *server.Room, userId int64, name string) *Ingame {
+	return &Ingame{
+		room:   room,
+		userId: userId,
+		player: &objects.Player{
+			Name: name,
+		},
+	}
 }
 
 func (s *Ingame) Name() string {
 	return "Ingame"
 }
 
-func (s *Ingame) SetClient(client server.ClientInterface) {
+func (s *Ingame) SetClient(client server.StateClient) {
 	s.client = client
-	var loggingPrefix = fmt.Sprintf("[%s] ", s.Name())
-	s.logger = log.New(log.Writer(), loggingPrefix, log.LstdFlags)
+	s.logger = client.Logger()
 }
 
 func (g *Ingame) HandleMessage(senderId uint64, msg packets.Msg) {
 	switch message := msg.(type) {
+	case *packets.Packet_Chat:
+		g.handleChat(senderId, message)
+	case *packets.Packet_Id:
+		g.handleId(senderId, message)
 	case *packets.Packet_Player:
 		g.handlePlayer(senderId, message)
 	case *packets.Packet_PlayerDirection:
 		g.handlePlayerDirection(senderId, message)
+	case *packets.Packet_PlayerConsumed:
+		g.handlePlayerConsumed(senderId, message)
+	case *packets.Packet_Spore:
+		g.handleSpore(senderId, message)
+	case *packets.Packet_SporeConsumed:
+		g.handleSporeConsumed(senderId, message)
+	case *packets.Packet_Spectate:
+		g.handleSpectate(senderId, message)
+	case *packets.Packet_RoomListRequest:
+		if senderId == g.client.Id() {
+			sendRoomList(g.client)
+		}
+	case *packets.Packet_JoinRoom:
+		g.handleJoinRoom(senderId, message)
+	case *packets.Packet_LeaveRoom:
+		g.handleLeaveRoom(senderId, message)
+	case *packets.Packet_JoinQueue:
+		if senderId == g.client.Id() {
+			joinQueue(g.client, g.userId, g.player.Name)
+		}
+	case *packets.Packet_MatchResult:
+		g.handleMatchResult(senderId, message)
+	}
+}
+func (g *Ingame) handleChat(senderId uint64, message *packets.Packet_Chat) {
+	if senderId == g.client.Id() {
+		if g.client.Muted() {
+			g.client.SocketSendAs(0, packets.NewChat("You are muted"))
+			return
+		}
+		g.client.Broadcast(message)
+	} else {
+		g.client.SocketSendAs(senderId, message)
 	}
 }
+
+// Another player has left, so our client can remove them
+func (g *Ingame) handleId(senderId uint64, message *packets.Packet_Id) {
+	if senderId != g.client.Id() {
+		g.client.SocketSendAs(senderId, message)
+	}
+}
+
 func (g *Ingame) handlePlayer(senderId uint64, message *packets.Packet_Player) {
 	if senderId == g.client.Id() {
-		g.logger.Println("Received player message from our own client, ignoring")
+		g.logger.Debug("Received player message from our own client, ignoring")
 		return
 	}
 	g.client.SocketSendAs(senderId, message)
 }
 
 func (s *Ingame) OnExit() {
-	if s.cancelPlayerUpdateLoop != nil {
-		s.cancelPlayerUpdateLoop()
-	}
 	s.client.SharedGameObjects().Players.Remove(s.client.Id())
+	// Let everyone else know we've left
+	s.client.Broadcast(packets.NewId(s.client.Id()))
+	s.saveStats()
+	s.client.Rooms().Leave(s.room, s.client, true)
+}
+
+func (s *Ingame) saveStats() {
+	if s.userId == 0 {
+		return
+	}
+	dbTx := s.client.DbTx()
+	ctx, cancel := dbTx.OpContext()
+	defer cancel()
+	err := dbTx.Stats.RecordGame(ctx, store.GameResult{
+		UserId:       s.userId,
+		Score:        s.bestScore,
+		PlayersEaten: s.playersEaten,
+	})
+	if err != nil {
+		s.logger.Error("Failed to save stats", "player", s.player.Name, "error", err)
+	}
 }
 
 func (s *Ingame) OnEnter() {
-	s.logger.Printf("Adding player %s to the shared collection", s.player.Name)
-	go s.client.SharedGameObjects().Players.Add(s.player, s.client.Id())
-	s.player.X = rand.Float64() * 100
-	s.player.Y = rand.Float64() * 100
-	s.player.Radius = 20
-	s.player.Speed = 140
+	s.room = s.client.Rooms().Join(s.room, s.client, true)
+	s.rng = s.client.SharedGameObjects().Random.Stream(s.client.Id())
+	s.player.History = objects.NewPositionHistory(s.client.Tuning().PositionHistorySize)
+	s.spawnPlayer()
+	s.player.Publish()
+	s.logger.Info("Adding player to the shared collection", "player", s.player.Name, "room", s.room.Id)
+	s.client.SharedGameObjects().Players.Add(s.player, s.client.Id())
 
 	// Send the initial player data to the client
 	s.client.SocketSend(packets.NewPlayer(s.client.Id(), s.player))
 
-	// Send information about all existing players to the new player
-	s.client.SharedGameObjects().Players.ForEach(func(playerId uint64, player *objects.Player) {
-		if playerId != s.client.Id() {
-			s.logger.Printf("Sending existing player %s to new player", player.Name)
-			s.client.SocketSendAs(playerId, packets.NewPlayer(playerId, player))
+	sendWorld(s.client)
+}
+
+// Tells the client about all the other players and the spores
+func sendWorld(client server.StateClient) {
+	client.SharedGameObjects().Players.ForEach(func(playerId uint64, player *objects.Player) {
+		if playerId != client.Id() {
+			client.Logger().Debug("Sending existing player to new player", "player", player.Name)
+			client.SocketSendAs(playerId, packets.NewPlayer(playerId, player.Snapshot()))
 		}
 	})
+
+	client.SharedGameObjects().Spores.ForEach(func(sporeId uint64, spore *objects.Spore) {
+		client.SocketSendAs(0, packets.NewSpore(sporeId, spore))
+	})
 }
 func (g *Ingame) syncPlayer(delta float64) {
+	tuning := g.client.Tuning()
+	g.player.Speed = tuning.SpeedFor(g.player.Radius)
+
 	newX := g.player.X + g.player.Speed*math.Cos(g.player.Direction)*delta
 	newY := g.player.Y + g.player.Speed*math.Sin(g.player.Direction)*delta
 
-	g.player.X = newX
-	g.player.Y = newY
+	// Keep the player inside the map
+	g.player.X = math.Max(0, math.Min(tuning.MapSize, newX))
+	g.player.Y = math.Max(0, math.Min(tuning.MapSize, newY))
+	g.player.Rtt = g.client.Rtt()
+	g.recordPosition(g.now())
+
+	g.sendPlayerUpdate()
+}
+
+// Puts the player somewhere random with its starting size
+func (g *Ingame) spawnPlayer() {
+	tuning := g.client.Tuning()
+	g.player.X = g.rng.Float64() * tuning.MapSize
+	g.player.Y = g.rng.Float64() * tuning.MapSize
+	g.player.Radius = tuning.InitialRadius
+	g.player.Speed = tuning.SpeedFor(g.player.Radius)
+	g.bestScore = max(g.bestScore, int64(g.player.Radius))
+	g.player.History.Clear()
+	g.player.Respawn()
+	g.recordPosition(g.now())
+}
+
+// The simulation's time, which isn't necessarily the wall clock's
+func (g *Ingame) now() time.Time {
+	return g.client.SharedGameObjects().Clock.Now()
+}
+
+func (g *Ingame) recordPosition(t time.Time) {
+	g.player.History.Record(g.currentPosition(t))
+}
+
+func (g *Ingame) currentPosition(t time.Time) objects.PositionSample {
+	return objects.PositionSample{
+		Time:   t,
+		X:      g.player.X,
+		Y:      g.player.Y,
+		Radius: g.player.Radius,
+	}
+}
+
+func lagCompensator(tuning *config.GameConfig) objects.LagCompensator {
+	return objects.LagCompensator{
+		MaxRewind:          tuning.MaxRewind.Duration,
+		InterpolationDelay: tuning.InterpolationDelay.Duration,
+	}
+}
 
+func (g *Ingame) sendPlayerUpdate() {
+	g.player.Publish()
 	updatePacket := packets.NewPlayer(g.client.Id(), g.player)
 	g.client.Broadcast(updatePacket)
-	go g.client.SocketSend(updatePacket)
-}
-func (g *Ingame) playerUpdateLoop(ctx context.Context) {
-	const delta float64 = 0.05
-	ticker := time.NewTicker(time.Duration(delta*1000) * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			g.syncPlayer(delta)
-		case <-ctx.Done():
-			return
-		}
+	g.client.SocketSend(packets.NewOwnPlayer(g.client.Id(), g.player, g.lastInputSequence, g.lastInputTimestamp))
+}
+
+// Moves the player on by delta seconds, once it's been told where to go
+func (g *Ingame) OnTick(delta float64) {
+	if !g.moving {
+		return
 	}
+	start := time.Now()
+	g.syncPlayer(delta)
+	metrics.TickDuration.With("player").Observe(time.Since(start).Seconds())
 }
+
 func (g *Ingame) handlePlayerDirection(senderId uint64, message *packets.Packet_PlayerDirection) {
 	if senderId == g.client.Id() {
+		// Inputs can arrive out of order, only the newest one counts. Clients that
+		// don't number their inputs send 0, which is always applied.
+		sequence := message.PlayerDirection.Sequence
+		if sequence != 0 && sequence <= g.lastInputSequence {
+			g.logger.Debug("Ignoring stale input", "sequence", sequence, "applied", g.lastInputSequence)
+			return
+		}
+		g.lastInputSequence = sequence
+		g.lastInputTimestamp = message.PlayerDirection.Timestamp
+
 		g.player.Direction = message.PlayerDirection.Direction
 
-		// If this is the first time receiving a player direction message from our client, start the player update loop
-		if g.cancelPlayerUpdateLoop == nil {
-			ctx, cancel := context.WithCancel(context.Background())
-			g.cancelPlayerUpdateLoop = cancel
-			go g.playerUpdateLoop(ctx)
+		g.moving = true
+	}
+}
+
+func (g *Ingame) handlePlayerConsumed(senderId uint64, message *packets.Packet_PlayerConsumed) {
+	targetId := message.PlayerConsumed.PlayerId
+
+	if senderId != g.client.Id() {
+		// Another player's state has checked that it ate us
+		if targetId == g.client.Id() {
+			g.logger.Info("Player was eaten", "player", g.player.Name, "eater_id", senderId)
+			g.client.SocketSendAs(senderId, message)
+			g.spawnPlayer()
+			g.sendPlayerUpdate()
 		}
+		return
+	}
+
+	if targetId == g.client.Id() {
+		g.logger.Warn("Client claims to have eaten itself, ignoring")
+		return
+	}
+	live, exists := g.client.SharedGameObjects().Players.Get(targetId)
+	if !exists {
+		g.logger.Debug("Client claims to have eaten a player that doesn't exist", "target_id", targetId)
+		return
+	}
+	target := live.Snapshot()
+
+	// Judge the claim against where the target was on our client's screen
+	now := g.now()
+	eater := g.currentPosition(now)
+	tuning := g.client.Tuning()
+	if !lagCompensator(tuning).CheckConsume(now, g.client.Rtt(), eater, target.History, tuning.EatRatio) {
+		g.logger.Info("Rejected claim to have eaten a player", "player", g.player.Name, "target", target.Name)
+		return
+	}
+
+	// Someone else may have got to it first, or our client may be repeating
+	// itself before the target has respawned
+	if !live.Claim(target.Life) {
+		g.logger.Debug("Player has already been eaten", "target_id", targetId)
+		return
+	}
+
+	// Grow by the target's area
+	g.player.Radius = math.Sqrt(g.player.Radius*g.player.Radius + target.Radius*target.Radius)
+	g.playersEaten++
+	g.bestScore = max(g.bestScore, int64(g.player.Radius))
+	g.recordPosition(now)
+	g.client.PassToPeer(message, targetId)
+	g.sendPlayerUpdate()
+}
+
+func (g *Ingame) handleSpore(senderId uint64, message *packets.Packet_Spore) {
+	g.client.SocketSendAs(senderId, message)
+}
+
+func (g *Ingame) handleSporeConsumed(senderId uint64, message *packets.Packet_SporeConsumed) {
+	if senderId != g.client.Id() {
+		// Another player ate it, or the server removed it
+		g.client.SocketSendAs(senderId, message)
+		return
+	}
+
+	sporeId := message.SporeConsumed.SporeId
+	spore, exists := g.client.SharedGameObjects().Spores.Get(sporeId)
+	if !exists {
+		g.logger.Debug("Client claims to have eaten a spore that doesn't exist", "spore_id", sporeId)
+		return
+	}
+
+	// Spores don't move, but our client may have touched it a moment ago at a
+	// position we've already moved on from
+	now := g.now()
+	sporeSample := objects.PositionSample{X: spore.X, Y: spore.Y, Radius: spore.Radius}
+	seen, _ := g.player.History.At(lagCompensator(g.client.Tuning()).ViewTime(now, g.client.Rtt()))
+	if !objects.Overlaps(g.currentPosition(now), sporeSample) && !objects.Overlaps(seen, sporeSample) {
+		g.logger.Info("Rejected claim to have eaten a spore", "player", g.player.Name, "spore_id", sporeId)
+		return
+	}
+
+	// Someone else may have got to it first
+	if _, taken := g.client.SharedGameObjects().Spores.Take(sporeId); !taken {
+		return
+	}
+
+	g.player.Radius = math.Sqrt(g.player.Radius*g.player.Radius + spore.Radius*spore.Radius)
+	g.bestScore = max(g.bestScore, int64(g.player.Radius))
+	g.recordPosition(now)
+	g.client.Broadcast(message)
+	g.client.SocketSend(message)
+	g.sendPlayerUpdate()
+}
+
+// Our client would rather watch for a while
+func (g *Ingame) handleSpectate(senderId uint64, message *packets.Packet_Spectate) {
+	if senderId == g.client.Id() {
+		g.client.SetState(NewSpectating(g.room, g.userId, g.player.Name, message.Spectate))
+	}
+}
+
+// Moves to another room, starting afresh there
+func (g *Ingame) handleJoinRoom(senderId uint64, message *packets.Packet_JoinRoom) {
+	if senderId != g.client.Id() {
+		return
+	}
+	room, ok := acceptJoin(g.client, message, true)
+	if !ok || room == nil || room == g.room {
+		// Already somewhere with space
+		return
+	}
+	g.client.SetState(NewIngame(room, g.userId, g.player.Name))
+}
+
+func (g *Ingame) handleLeaveRoom(senderId uint64, message *packets.Packet_LeaveRoom) {
+	if senderId != g.client.Id() {
+		return
+	}
+	g.client.SocketSend(packets.NewOkResponse())
+	g.client.SetState(NewLobby(g.userId, g.player.Name))
+}
+
+// Our ranked match is over, so back to the lobby to queue again or play casually
+func (g *Ingame) handleMatchResult(senderId uint64, message *packets.Packet_MatchResult) {
+	if senderId != 0 {
+		return
 	}
+	g.client.SocketSendAs(0, message)
+	g.client.SetState(NewLobby(g.userId, g.player.Name))
 }
@@ -0,0 +1,54 @@
+package states
+
+import (
+	"log/slog"
+	"server/internal/server"
+	"server/pkg/packets"
+)
+
+// Logged in, but not in any room. Clients can look at the rooms and pick one
+// to play or watch in, or queue for a ranked match.
+type Lobby struct {
+	client server.StateClient
+	logger *slog.Logger
+	userId int64
+	name   string
+}
+
+func NewLobby(userId int64, name string) *Lobby {
+	return &Lobby{userId: userId, name: name}
+}
+
+func (l *Lobby) Name() string {
+	return "Lobby"
+}
+
+func (l *Lobby) SetClient(client server.StateClient) {
+	l.client = client
+	l.logger = client.Logger()
+}
+
+func (l *Lobby) OnEnter() {
+	sendRoomList(l.client)
+}
+
+func (l *Lobby) OnExit() {}
+
+func (l *Lobby) HandleMessage(senderId uint64, msg packets.Msg) {
+	if senderId != l.client.Id() {
+		return
+	}
+
+	switch msg := msg.(type) {
+	case *packets.Packet_RoomListRequest:
+		sendRoomList(l.client)
+	case *packets.Packet_JoinRoom:
+		if room, ok := acceptJoin(l.client, msg, true); ok {
+			l.client.SetState(NewIngame(room, l.userId, l.name))
+		}
+	case *packets.Packet_Spectate:
+		l.client.SetState(NewSpectating(nil, l.userId, l.name, msg.Spectate))
+	case *packets.Packet_JoinQueue:
+		joinQueue(l.client, l.userId, l.name)
+	}
+}
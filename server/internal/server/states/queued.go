@@ -0,0 +1,114 @@
+package states
+
+import (
+	"errors"
+	"log/slog"
+	"server/internal/server"
+	"server/internal/server/matchmaking"
+	"server/internal/server/store"
+	"server/pkg/packets"
+)
+
+// Waiting outside any room for the matchmaker to find a ranked match. The
+// client is told how the wait is going every so often, and moves into the
+// match's room as soon as it starts.
+type Queued struct {
+	client server.StateClient
+	logger *slog.Logger
+	userId int64
+	name   string
+	rating matchmaking.Rating
+
+	// Set once we've left the state, so a match found just as the client left
+	// the queue doesn't drag it back in
+	exited bool
+}
+
+func NewQueued(userId int64, name string, rating matchmaking.Rating) *Queued {
+	return &Queued{userId: userId, name: name, rating: rating}
+}
+
+func (q *Queued) Name() string {
+	return "Queued"
+}
+
+func (q *Queued) SetClient(client server.StateClient) {
+	q.client = client
+	q.logger = client.Logger()
+}
+
+func (q *Queued) OnEnter() {
+	q.logger.Info("Joined the ranked queue", "rating", q.rating.Rating)
+	q.client.Matchmaker().Enqueue(&server.QueueEntry{
+		MatchPlayer: server.MatchPlayer{
+			ClientId: q.client.Id(),
+			UserId:   q.userId,
+			Name:     q.name,
+			Rating:   q.rating,
+		},
+		Client: q.client,
+		Start:  q.start,
+	})
+}
+
+func (q *Queued) OnExit() {
+	q.exited = true
+	q.client.Matchmaker().Dequeue(q.client.Id())
+}
+
+// Called by the matchmaker once the match's room is open
+func (q *Queued) start(room *server.Room) {
+	q.client.Deliver(func() {
+		if !q.exited {
+			q.client.SetState(NewIngame(room, q.userId, q.name))
+		}
+	})
+}
+
+func (q *Queued) HandleMessage(senderId uint64, msg packets.Msg) {
+	if senderId != q.client.Id() {
+		return
+	}
+
+	switch msg := msg.(type) {
+	case *packets.Packet_JoinQueue:
+		q.client.SocketSend(packets.NewDenyResponse("You're already in the queue"))
+	case *packets.Packet_LeaveQueue:
+		q.client.SocketSend(packets.NewOkResponse())
+		q.client.SetState(NewLobby(q.userId, q.name))
+	case *packets.Packet_RoomListRequest:
+		sendRoomList(q.client)
+	case *packets.Packet_JoinRoom:
+		if room, ok := acceptJoin(q.client, msg, true); ok {
+			q.client.SetState(NewIngame(room, q.userId, q.name))
+		}
+	case *packets.Packet_Spectate:
+		q.client.SetState(NewSpectating(nil, q.userId, q.name, msg.Spectate))
+	}
+}
+
+// Looks up the user's rating and puts the client in the ranked queue, saying
+// ok, or no if it's a visitor or the rating can't be loaded
+func joinQueue(client server.StateClient, userId int64, name string) {
+	if userId == 0 {
+		client.SocketSend(packets.NewDenyResponse("Log in to play ranked"))
+		return
+	}
+
+	dbTx := client.DbTx()
+	ctx, cancel := dbTx.OpContext()
+	defer cancel()
+	rating := client.Matchmaker().InitialRating()
+	stored, err := dbTx.Ratings.GetRating(ctx, userId)
+	switch {
+	case err == nil:
+		rating = matchmaking.Rating{Rating: stored.Rating, Deviation: stored.Deviation}
+	case !errors.Is(err, store.ErrNotFound):
+		client.Logger().Error("Failed to load rating", "error", err)
+		client.SocketSend(packets.NewDenyResponse("Ranked play isn't available right now"))
+		return
+	}
+
+	client.SocketSend(packets.NewOkResponse())
+	client.SetState(NewQueued(userId, name, rating))
+}
@@ -0,0 +1,43 @@
+package states
+
+import (
+	"server/internal/server"
+	"server/pkg/packets"
+)
+
+// Answers a RoomListRequest with every open room
+func sendRoomList(client server.StateClient) {
+	rooms := client.Rooms().List()
+	infos := make([]*packets.RoomInfo, 0, len(rooms))
+	for _, room := range rooms {
+		infos = append(infos, room.Info())
+	}
+	client.SocketSendAs(0, packets.NewRoomList(infos))
+}
+
+// Finds the room a JoinRoom asks for, nil meaning any with space, and says ok.
+// Says no and returns false if the room doesn't exist, or if the client wants
+// to play there and it's full or a ranked match the client isn't in.
+func acceptJoin(client server.StateClient, message *packets.Packet_JoinRoom, playing bool) (*server.Room, bool) {
+	roomId := message.JoinRoom.RoomId
+	if roomId == 0 {
+		client.SocketSend(packets.NewOkResponse())
+		return nil, true
+	}
+
+	room, exists := client.Rooms().Get(roomId)
+	if !exists {
+		client.SocketSend(packets.NewDenyResponse("That room doesn't exist"))
+		return nil, false
+	}
+	if playing && room != client.Room() && !room.Admits(client) {
+		if room.Ranked() {
+			client.SocketSend(packets.NewDenyResponse("That room is for a ranked match"))
+		} else {
+			client.SocketSend(packets.NewDenyResponse("That room is full"))
+		}
+		return nil, false
+	}
+	client.SocketSend(packets.NewOkResponse())
+	return room, true
+}
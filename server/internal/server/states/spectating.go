@@ -0,0 +1,169 @@
+package states
+
+import (
+	"log/slog"
+	"server/internal/server"
+	"server/internal/server/objects"
+	"server/pkg/packets"
+)
+
+// Watching a room without a player in it: follows the biggest player, a chosen
+// one, or nobody while the client moves its camera around by itself. Visitors
+// can watch without logging in. Logged in spectators start playing in the room
+// as soon as they send a direction.
+type Spectating struct {
+	client server.StateClient
+	logger *slog.Logger
+	room   *server.Room
+
+	// Who the spectator is, if they're logged in
+	userId int64
+	name   string
+
+	// What the client asked for, and the player we last told it it's following
+	mode     packets.SpectateMessage_Mode
+	chosenId uint64
+	targetId uint64
+	told     bool
+}
+
+// Watches the room, or the busiest one if it's nil
+func NewSpectating(room *server.Room, userId int64, name string, request *packets.SpectateMessage) *Spectating {
+	return &Spectating{
+		room:     room,
+		userId:   userId,
+		name:     name,
+		mode:     request.Mode,
+		chosenId: request.PlayerId,
+	}
+}
+
+func (s *Spectating) Name() string {
+	return "Spectating"
+}
+
+func (s *Spectating) SetClient(client server.StateClient) {
+	s.client = client
+	s.logger = client.Logger()
+}
+
+func (s *Spectating) OnEnter() {
+	s.room = s.client.Rooms().Join(s.room, s.client, false)
+	sendWorld(s.client)
+	s.follow()
+}
+
+func (s *Spectating) OnExit() {
+	s.client.Rooms().Leave(s.room, s.client, false)
+}
+
+func (s *Spectating) HandleMessage(senderId uint64, msg packets.Msg) {
+	if senderId == s.client.Id() {
+		switch msg := msg.(type) {
+		case *packets.Packet_Spectate:
+			s.mode = msg.Spectate.Mode
+			s.chosenId = msg.Spectate.PlayerId
+			s.follow()
+		case *packets.Packet_PlayerDirection:
+			s.play()
+		case *packets.Packet_RoomListRequest:
+			sendRoomList(s.client)
+		case *packets.Packet_JoinRoom:
+			s.joinRoom(msg)
+		case *packets.Packet_LeaveRoom:
+			s.leaveRoom()
+		case *packets.Packet_JoinQueue:
+			joinQueue(s.client, s.userId, s.name)
+		}
+		return
+	}
+
+	// Everything else is news from the world
+	switch msg.(type) {
+	case *packets.Packet_Chat, *packets.Packet_Id, *packets.Packet_Player, *packets.Packet_Spore, *packets.Packet_SporeConsumed:
+		s.client.SocketSendAs(senderId, msg)
+	case *packets.Packet_MatchResult:
+		// The ranked match we were watching is over, so watch something else
+		s.client.SocketSendAs(senderId, msg)
+		s.client.SetState(NewSpectating(nil, s.userId, s.name, &packets.SpectateMessage{Mode: s.mode, PlayerId: s.chosenId}))
+	}
+}
+
+// The leader changes and players leave, so check who we're following every tick
+func (s *Spectating) OnTick(delta float64) {
+	s.follow()
+}
+
+// Works out who to follow and tells the client if that's changed. A chosen
+// player who has left is replaced by the leader.
+func (s *Spectating) follow() {
+	players := s.room.World.Players
+
+	var targetId uint64
+	switch s.mode {
+	case packets.SpectateMessage_FOLLOW_PLAYER:
+		if _, exists := players.Get(s.chosenId); exists {
+			targetId = s.chosenId
+			break
+		}
+		s.logger.Debug("Player to follow isn't in the game, following the leader", "player_id", s.chosenId)
+		s.mode = packets.SpectateMessage_FOLLOW_LEADER
+		targetId = leaderId(players)
+	case packets.SpectateMessage_FOLLOW_LEADER:
+		targetId = leaderId(players)
+	}
+
+	if s.told && targetId == s.targetId {
+		return
+	}
+	s.targetId = targetId
+	s.told = true
+	s.client.SocketSendAs(0, packets.NewSpectate(s.mode, targetId))
+}
+
+// Puts a logged in spectator back in the game
+func (s *Spectating) play() {
+	if s.userId == 0 {
+		s.client.SocketSendAs(0, packets.NewChat("Log in to play"))
+		return
+	}
+	s.client.SetState(NewIngame(s.room, s.userId, s.name))
+}
+
+// Logged in spectators go and play in the room, visitors watch it
+func (s *Spectating) joinRoom(message *packets.Packet_JoinRoom) {
+	loggedIn := s.userId != 0
+	room, ok := acceptJoin(s.client, message, loggedIn)
+	if !ok {
+		return
+	}
+	if loggedIn {
+		s.client.SetState(NewIngame(room, s.userId, s.name))
+		return
+	}
+	s.client.SetState(NewSpectating(room, 0, "", &packets.SpectateMessage{Mode: s.mode, PlayerId: s.chosenId}))
+}
+
+func (s *Spectating) leaveRoom() {
+	s.client.SocketSend(packets.NewOkResponse())
+	if s.userId != 0 {
+		s.client.SetState(NewLobby(s.userId, s.name))
+		return
+	}
+	s.client.SetState(&Connected{})
+}
+
+// The id of the biggest player, the oldest one if it's a tie, or 0 if the world
+// is empty
+func leaderId(players *objects.SharedCollection[*objects.Player]) uint64 {
+	var leader uint64
+	var biggest float64
+	for _, id := range players.Ids() {
+		if player, exists := players.Get(id); exists {
+			if radius := player.Snapshot().Radius; leader == 0 || radius > biggest {
+				leader, biggest = id, radius
+			}
+		}
+	}
+	return leader
+}
@@ -0,0 +1,234 @@
+package store
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Keeps everything in maps, for tests and for running without a database.
+// Nothing survives a restart.
+type memoryStore struct {
+	// A mutex, or nothing inside a transaction, which holds the mutex throughout
+	lock sync.Locker
+	data *memoryData
+}
+
+type memoryData struct {
+	users   map[int64]User
+	byName  map[string]int64
+	nextId  int64
+	stats   map[int64]Stats
+	bans    map[int64]Ban
+	ratings map[int64]Rating
+}
+
+func NewMemory() Store {
+	return &memoryStore{
+		lock: &sync.Mutex{},
+		data: &memoryData{
+			users:   make(map[int64]User),
+			byName:  make(map[string]int64),
+			nextId:  1,
+			stats:   make(map[int64]Stats),
+			bans:    make(map[int64]Ban),
+			ratings: make(map[int64]Rating),
+		},
+	}
+}
+
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		users:   maps.Clone(d.users),
+		byName:  maps.Clone(d.byName),
+		nextId:  d.nextId,
+		stats:   maps.Clone(d.stats),
+		bans:    maps.Clone(d.bans),
+		ratings: maps.Clone(d.ratings),
+	}
+}
+
+type noLock struct{}
+
+func (noLock) Lock()   {}
+func (noLock) Unlock() {}
+
+// Runs fn with everything else locked out, putting the data back the way it
+// was if fn fails
+func (m *memoryStore) WithTx(ctx context.Context, fn func(tx Tx) error) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	backup := m.data.clone()
+	err := fn(&memoryStore{lock: noLock{}, data: m.data})
+	if err == nil {
+		// Like a database, don't commit once the context is done
+		err = ctx.Err()
+	}
+	if err != nil {
+		*m.data = *backup
+	}
+	return err
+}
+
+func (m *memoryStore) Migrate(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (m *memoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
+
+func (m *memoryStore) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	id, exists := m.data.byName[username]
+	if !exists {
+		return User{}, ErrNotFound
+	}
+	return m.data.users[id], nil
+}
+
+func (m *memoryStore) GetUserById(ctx context.Context, id int64) (User, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	user, exists := m.data.users[id]
+	if !exists {
+		return User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (m *memoryStore) CreateUser(ctx context.Context, username, passwordHash string) (User, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, exists := m.data.byName[username]; exists {
+		return User{}, ErrUserExists
+	}
+	user := User{Id: m.data.nextId, Username: username, PasswordHash: passwordHash}
+	m.data.nextId++
+	m.data.users[user.Id] = user
+	m.data.byName[username] = user.Id
+	return user, nil
+}
+
+func (m *memoryStore) ListUsers(ctx context.Context) ([]UserSummary, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	users := make([]UserSummary, 0, len(m.data.users))
+	for id, user := range m.data.users {
+		ban, banned := m.data.bans[id]
+		users = append(users, UserSummary{
+			User:   User{Id: user.Id, Username: user.Username},
+			Stats:  m.data.stats[id],
+			Banned: banned,
+			Ban:    ban,
+		})
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Id < users[j].Id
+	})
+	return users, nil
+}
+
+func (m *memoryStore) UpdateUsername(ctx context.Context, id int64, username string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	user, exists := m.data.users[id]
+	if !exists {
+		return nil
+	}
+	if otherId, taken := m.data.byName[username]; taken && otherId != id {
+		return ErrUserExists
+	}
+	delete(m.data.byName, user.Username)
+	user.Username = username
+	m.data.users[id] = user
+	m.data.byName[username] = id
+	return nil
+}
+
+func (m *memoryStore) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if user, exists := m.data.users[id]; exists {
+		user.PasswordHash = passwordHash
+		m.data.users[id] = user
+	}
+	return nil
+}
+
+func (m *memoryStore) GetBan(ctx context.Context, userId int64) (Ban, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	ban, exists := m.data.bans[userId]
+	if !exists {
+		return Ban{}, ErrNotFound
+	}
+	return ban, nil
+}
+
+func (m *memoryStore) BanUser(ctx context.Context, userId int64, reason string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.data.bans[userId] = Ban{UserId: userId, Reason: reason, CreatedAt: time.Now()}
+	return nil
+}
+
+func (m *memoryStore) UnbanUser(ctx context.Context, userId int64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.data.bans, userId)
+	return nil
+}
+
+func (m *memoryStore) RecordGame(ctx context.Context, result GameResult) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	stats := m.data.stats[result.UserId]
+	stats.BestScore = max(stats.BestScore, result.Score)
+	stats.GamesPlayed++
+	stats.PlayersEaten += result.PlayersEaten
+	m.data.stats[result.UserId] = stats
+	return nil
+}
+
+func (m *memoryStore) GetRating(ctx context.Context, userId int64) (Rating, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	rating, exists := m.data.ratings[userId]
+	if !exists {
+		return Rating{}, ErrNotFound
+	}
+	return rating, nil
+}
+
+func (m *memoryStore) RecordMatch(ctx context.Context, result MatchResult) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	rating := m.data.ratings[result.UserId]
+	rating.UserId = result.UserId
+	rating.Rating = result.Rating
+	rating.Deviation = result.Deviation
+	rating.MatchesPlayed++
+	m.data.ratings[result.UserId] = rating
+	return nil
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"server/internal/server/db"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// The sqlc queries over a SQLite database
+type sqliteStore struct {
+	conn    *sql.DB
+	queries *db.Queries
+}
+
+func OpenSqlite(path string) (Store, error) {
+	conn, err := OpenSqliteDb(path)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteStore{
+		conn:    conn,
+		queries: db.New(timedDb{conn}),
+	}, nil
+}
+
+// Opens the database at path with the settings everything using it needs,
+// whether through the store or directly like the migrate command
+func OpenSqliteDb(path string) (*sql.DB, error) {
+	// Wait for other connections' writes instead of failing straight away
+	dsn := path + "?_pragma=busy_timeout(5000)"
+	if strings.Contains(path, "?") {
+		dsn = path + "&_pragma=busy_timeout(5000)"
+	}
+	return sql.Open("sqlite", dsn)
+}
+
+func (s *sqliteStore) Migrate(ctx context.Context) ([]string, error) {
+	migrations, err := db.Migrate(ctx, s.conn)
+	applied := make([]string, len(migrations))
+	for i, m := range migrations {
+		applied[i] = fmt.Sprintf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	return applied, err
+}
+
+func (s *sqliteStore) WithTx(ctx context.Context, fn func(tx Tx) error) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Not s.queries.WithTx(tx): that hands sqlc the bare *sql.Tx, and queries
+	// in a transaction would no longer be timed or show up in the metrics
+	if err := fn(&sqliteStore{conn: s.conn, queries: db.New(timedDb{tx})}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Ping(ctx context.Context) error {
+	return s.conn.PingContext(ctx)
+}
+
+func (s *sqliteStore) Close() error {
+	return s.conn.Close()
+}
+
+func (s *sqliteStore) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	user, err := s.queries.GetUserByUsername(ctx, username)
+	return fromDbUser(user), translateError(err)
+}
+
+func (s *sqliteStore) GetUserById(ctx context.Context, id int64) (User, error) {
+	user, err := s.queries.GetUserByID(ctx, id)
+	return fromDbUser(user), translateError(err)
+}
+
+func (s *sqliteStore) CreateUser(ctx context.Context, username, passwordHash string) (User, error) {
+	user, err := s.queries.CreateUser(ctx, db.CreateUserParams{
+		Username:     username,
+		PasswordHash: passwordHash,
+	})
+	return fromDbUser(user), translateError(err)
+}
+
+func (s *sqliteStore) ListUsers(ctx context.Context) ([]UserSummary, error) {
+	rows, err := s.queries.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]UserSummary, len(rows))
+	for i, row := range rows {
+		users[i] = UserSummary{
+			User: User{Id: row.ID, Username: row.Username},
+			Stats: Stats{
+				BestScore:    row.BestScore,
+				GamesPlayed:  row.GamesPlayed,
+				PlayersEaten: row.PlayersEaten,
+			},
+		}
+		if row.BanReason.Valid {
+			users[i].Banned = true
+			users[i].Ban = Ban{UserId: row.ID, Reason: row.BanReason.String}
+		}
+	}
+	return users, nil
+}
+
+func (s *sqliteStore) UpdateUsername(ctx context.Context, id int64, username string) error {
+	err := s.queries.UpdateUsername(ctx, db.UpdateUsernameParams{Username: username, ID: id})
+	return translateError(err)
+}
+
+func (s *sqliteStore) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error {
+	return s.queries.UpdatePasswordHash(ctx, db.UpdatePasswordHashParams{PasswordHash: passwordHash, ID: id})
+}
+
+func (s *sqliteStore) GetBan(ctx context.Context, userId int64) (Ban, error) {
+	ban, err := s.queries.GetBan(ctx, userId)
+	return Ban{UserId: ban.UserID, Reason: ban.Reason, CreatedAt: ban.CreatedAt}, translateError(err)
+}
+
+func (s *sqliteStore) BanUser(ctx context.Context, userId int64, reason string) error {
+	return s.queries.BanUser(ctx, db.BanUserParams{UserID: userId, Reason: reason})
+}
+
+func (s *sqliteStore) UnbanUser(ctx context.Context, userId int64) error {
+	return s.queries.UnbanUser(ctx, userId)
+}
+
+func (s *sqliteStore) RecordGame(ctx context.Context, result GameResult) error {
+	return s.queries.UpsertPlayerStats(ctx, db.UpsertPlayerStatsParams{
+		UserID:       result.UserId,
+		BestScore:    result.Score,
+		PlayersEaten: result.PlayersEaten,
+	})
+}
+
+func (s *sqliteStore) GetRating(ctx context.Context, userId int64) (Rating, error) {
+	rating, err := s.queries.GetRating(ctx, userId)
+	return Rating{
+		UserId:        rating.UserID,
+		Rating:        rating.Rating,
+		Deviation:     rating.Deviation,
+		MatchesPlayed: rating.MatchesPlayed,
+	}, translateError(err)
+}
+
+func (s *sqliteStore) RecordMatch(ctx context.Context, result MatchResult) error {
+	return s.queries.UpsertRating(ctx, db.UpsertRatingParams{
+		UserID:    result.UserId,
+		Rating:    result.Rating,
+		Deviation: result.Deviation,
+	})
+}
+
+func fromDbUser(user db.User) User {
+	return User{Id: user.ID, Username: user.Username, PasswordHash: user.PasswordHash}
+}
+
+// Turns the errors callers care about into ours
+func translateError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return ErrUserExists
+	}
+	return err
+}
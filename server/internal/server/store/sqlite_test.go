@@ -0,0 +1,113 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"server/internal/server/db"
+	"server/internal/server/store"
+	"testing"
+	"time"
+)
+
+// Writes the baseline fixture to a new database file and returns its path
+func baselineDatabase(t *testing.T) string {
+	t.Helper()
+	fixture, err := os.ReadFile(filepath.Join("testdata", "baseline.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.db")
+	conn, err := store.OpenSqliteDb(path)
+	if err != nil {
+		t.Fatalf("opening %s: %v", path, err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec(string(fixture)); err != nil {
+		t.Fatalf("loading the fixture: %v", err)
+	}
+	return path
+}
+
+func TestMigrateBaseline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := store.OpenSqlite(baselineDatabase(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	applied, err := s.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	migrations, err := db.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("applied %v, want all %d migrations", applied, len(migrations))
+	}
+	if again, err := s.Migrate(ctx); err != nil || len(again) != 0 {
+		t.Errorf("migrating again applied %v with error %v, want nothing", again, err)
+	}
+
+	alice, err := s.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatalf("getting alice: %v", err)
+	}
+	if alice.Id != 1 || alice.PasswordHash != "$2a$10$aliceHashaliceHashaliceHashaliceHashaliceHashalice" {
+		t.Errorf("alice is %+v after migrating", alice)
+	}
+
+	users, err := s.ListUsers(ctx)
+	if err != nil {
+		t.Fatalf("listing users: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("got %d users, want 2", len(users))
+	}
+	if want := (store.Stats{BestScore: 1200, GamesPlayed: 7, PlayersEaten: 3}); users[0].Stats != want {
+		t.Errorf("alice's stats are %+v, want %+v", users[0].Stats, want)
+	}
+
+	ban, err := s.GetBan(ctx, 2)
+	if err != nil {
+		t.Fatalf("getting bob's ban: %v", err)
+	}
+	if ban.Reason != "Cheating" || !ban.CreatedAt.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("bob's ban is %+v after migrating", ban)
+	}
+
+	// Tables the baseline didn't have work too
+	if _, err := s.GetRating(ctx, 1); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("getting a rating nobody has got %v, want %v", err, store.ErrNotFound)
+	}
+	if err := s.RecordMatch(ctx, store.MatchResult{UserId: 1, Rating: 1550, Deviation: 300}); err != nil {
+		t.Fatalf("recording a match: %v", err)
+	}
+	rating, err := s.GetRating(ctx, 1)
+	if err != nil || rating.Rating != 1550 || rating.MatchesPlayed != 1 {
+		t.Errorf("got rating %+v with error %v after one match", rating, err)
+	}
+}
+
+func TestOpenSqliteDbWaitsForLocks(t *testing.T) {
+	for _, path := range []string{"plain.db", "with-options.db?_txlock=immediate"} {
+		conn, err := store.OpenSqliteDb(filepath.Join(t.TempDir(), path))
+		if err != nil {
+			t.Fatalf("opening %s: %v", path, err)
+		}
+		var timeout int
+		if err := conn.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+			t.Fatalf("reading busy_timeout for %s: %v", path, err)
+		}
+		conn.Close()
+		if timeout != 5000 {
+			t.Errorf("%s has busy_timeout %d, want 5000", path, timeout)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Persistent storage for users and their stats, behind interfaces so that
+// gameplay code doesn't depend on any particular database
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"server/internal/server/config"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrUserExists = errors.New("user already exists")
+)
+
+type User struct {
+	Id           int64
+	Username     string
+	PasswordHash string
+}
+
+type Ban struct {
+	UserId    int64
+	Reason    string
+	CreatedAt time.Time
+}
+
+// A user along with their stats and ban, for listing
+type UserSummary struct {
+	User
+	Stats  Stats
+	Banned bool
+	Ban    Ban
+}
+
+type Stats struct {
+	BestScore    int64
+	GamesPlayed  int64
+	PlayersEaten int64
+}
+
+// The outcome of one game, added to the user's stats
+type GameResult struct {
+	UserId       int64
+	Score        int64
+	PlayersEaten int64
+}
+
+// A user's ranked rating, see the matchmaking package
+type Rating struct {
+	UserId        int64
+	Rating        float64
+	Deviation     float64
+	MatchesPlayed int64
+}
+
+// A user's new rating after a ranked match
+type MatchResult struct {
+	UserId    int64
+	Rating    float64
+	Deviation float64
+}
+
+type UserStore interface {
+	// Returns ErrNotFound if there's no such user
+	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetUserById(ctx context.Context, id int64) (User, error)
+
+	// Returns ErrUserExists if the username is taken
+	CreateUser(ctx context.Context, username, passwordHash string) (User, error)
+
+	ListUsers(ctx context.Context) ([]UserSummary, error)
+	UpdateUsername(ctx context.Context, id int64, username string) error
+	UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error
+
+	// Returns ErrNotFound if the user isn't banned
+	GetBan(ctx context.Context, userId int64) (Ban, error)
+
+	// Bans the user, replacing any ban they already had
+	BanUser(ctx context.Context, userId int64, reason string) error
+	UnbanUser(ctx context.Context, userId int64) error
+}
+
+type StatsStore interface {
+	RecordGame(ctx context.Context, result GameResult) error
+}
+
+type RatingStore interface {
+	// Returns ErrNotFound if the user hasn't finished a ranked match yet
+	GetRating(ctx context.Context, userId int64) (Rating, error)
+
+	// Replaces the user's rating, counting one more match played
+	RecordMatch(ctx context.Context, result MatchResult) error
+}
+
+// Everything that can be done inside a transaction
+type Tx interface {
+	UserStore
+	StatsStore
+	RatingStore
+}
+
+// A storage backend the server can run on
+type Store interface {
+	UserStore
+	StatsStore
+	RatingStore
+
+	// Runs fn in a transaction, which is committed if fn returns nil and rolled
+	// back otherwise
+	WithTx(ctx context.Context, fn func(tx Tx) error) error
+
+	// Brings the storage up to date, returning a description of each change made
+	Migrate(ctx context.Context) ([]string, error)
+
+	// Checks that the storage is reachable
+	Ping(ctx context.Context) error
+
+	Close() error
+}
+
+// Opens the backend chosen in the config
+func Open(cfg config.ServerConfig) (Store, error) {
+	switch cfg.Storage {
+	case "sqlite":
+		return OpenSqlite(cfg.DatabasePath)
+	case "memory":
+		return NewMemory(), nil
+	}
+	return nil, fmt.Errorf("unknown storage %q", cfg.Storage)
+}
+
+type loggerKey struct{}
+
+// Returns a context whose queries are logged with whichever logger the function
+// returns at the time, so the logger can change over the context's life
+func WithLogger(ctx context.Context, logger func() *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(func() *slog.Logger); ok {
+		return logger()
+	}
+	return slog.Default()
+}
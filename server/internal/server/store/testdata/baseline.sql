@@ -0,0 +1,30 @@
+-- A database as servers wrote it before there were migrations, from the old
+-- schema.sql, with a few users in it
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS player_stats (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    best_score INTEGER NOT NULL DEFAULT 0,
+    games_played INTEGER NOT NULL DEFAULT 0,
+    players_eaten INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS bans (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO users (username, password_hash) VALUES
+    ('alice', '$2a$10$aliceHashaliceHashaliceHashaliceHashaliceHashalice'),
+    ('bob', '$2a$10$bobHashbobHashbobHashbobHashbobHashbobHashbobHashbo');
+
+INSERT INTO player_stats (user_id, best_score, games_played, players_eaten) VALUES
+    (1, 1200, 7, 3);
+
+INSERT INTO bans (user_id, reason, created_at) VALUES
+    (2, 'Cheating', '2024-05-01 12:00:00');
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"server/internal/server/db"
+	"server/internal/server/metrics"
+	"strings"
+	"time"
+)
+
+// Wraps a database connection to record and log how long each query takes
+type timedDb struct {
+	db db.DBTX
+}
+
+func (t timedDb) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer observe(ctx, query, time.Now())
+	return t.db.ExecContext(ctx, query, args...)
+}
+
+func (t timedDb) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	defer observe(ctx, query, time.Now())
+	return t.db.PrepareContext(ctx, query)
+}
+
+func (t timedDb) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer observe(ctx, query, time.Now())
+	return t.db.QueryContext(ctx, query, args...)
+}
+
+func (t timedDb) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer observe(ctx, query, time.Now())
+	return t.db.QueryRowContext(ctx, query, args...)
+}
+
+func observe(ctx context.Context, query string, start time.Time) {
+	name := queryName(query)
+	elapsed := time.Since(start)
+	metrics.DbQueryDuration.With(name).Observe(elapsed.Seconds())
+	loggerFrom(ctx).Debug("Ran query", "query", name, "duration", elapsed)
+}
+
+// sqlc starts every query with a "-- name: GetUserByUsername :one" comment
+func queryName(query string) string {
+	const prefix = "-- name: "
+	if !strings.HasPrefix(query, prefix) {
+		return "other"
+	}
+	name, _, _ := strings.Cut(query[len(prefix):], " ")
+	return name
+}
@@ -0,0 +1,310 @@
+// A headless client that speaks the game's protocol over a WebSocket, for load
+// tests and anything else that needs players without running the Godot client
+package bot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"server/pkg/packets"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+)
+
+// How often the bot measures its round-trip time
+const pingPeriod = time.Second
+
+const writeWait = 10 * time.Second
+
+var ErrClosed = errors.New("connection closed")
+
+// The server answered a request with a DenyResponse
+type DeniedError struct {
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return "denied: " + e.Reason
+}
+
+// What a bot has seen so far
+type Stats struct {
+	PacketsSent     uint64
+	PacketsReceived uint64
+
+	// Player updates about the bot itself, and about everyone else
+	OwnUpdates   uint64
+	OtherUpdates uint64
+
+	// Direction inputs sent, and the sequence number of the newest one the
+	// server has acknowledged applying. Inputs are numbered from 1, so the
+	// difference is how many were never applied.
+	InputsSent uint64
+	LastAcked  uint32
+
+	RttSamples uint64
+	RttTotal   time.Duration
+	RttMax     time.Duration
+}
+
+func (s Stats) AverageRtt() time.Duration {
+	if s.RttSamples == 0 {
+		return 0
+	}
+	return s.RttTotal / time.Duration(s.RttSamples)
+}
+
+type Bot struct {
+	conn *websocket.Conn
+
+	// Guards writes to the connection and the input sequence
+	writeMux sync.Mutex
+	sequence uint32
+
+	id        atomic.Uint64
+	gotId     chan struct{}
+	responses chan packets.Msg
+
+	statsMux sync.Mutex
+	stats    Stats
+
+	// Closed once the read loop has stopped, after err is set
+	done chan struct{}
+	err  error
+}
+
+// Connects to the server's websocket endpoint, e.g. ws://localhost:8080/ws,
+// and waits for it to assign the bot an id
+func Dial(ctx context.Context, url string) (*Bot, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bot{
+		conn:      conn,
+		gotId:     make(chan struct{}),
+		responses: make(chan packets.Msg, 4),
+		done:      make(chan struct{}),
+	}
+	go b.readLoop()
+	go b.pingLoop()
+
+	select {
+	case <-b.gotId:
+		return b, nil
+	case <-b.done:
+		return nil, b.err
+	case <-ctx.Done():
+		b.Close()
+		return nil, ctx.Err()
+	}
+}
+
+func (b *Bot) Id() uint64 {
+	return b.id.Load()
+}
+
+func (b *Bot) Stats() Stats {
+	b.statsMux.Lock()
+	defer b.statsMux.Unlock()
+
+	return b.stats
+}
+
+// Closed once the connection has been lost or closed
+func (b *Bot) Done() <-chan struct{} {
+	return b.done
+}
+
+// Why the connection ended, once Done is closed
+func (b *Bot) Err() error {
+	<-b.done
+	return b.err
+}
+
+func (b *Bot) Register(ctx context.Context, username, password string) error {
+	return b.request(ctx, packets.NewRegisterRequest(username, password))
+}
+
+func (b *Bot) Login(ctx context.Context, username, password string) error {
+	return b.request(ctx, packets.NewLoginRequest(username, password))
+}
+
+// Sends the request and waits for the server's answer, returning a
+// *DeniedError if it says no
+func (b *Bot) request(ctx context.Context, msg packets.Msg) error {
+	if err := b.Send(msg); err != nil {
+		return err
+	}
+
+	select {
+	case response := <-b.responses:
+		if deny, ok := response.(*packets.Packet_DenyResponse); ok {
+			return &DeniedError{Reason: deny.DenyResponse.Reason}
+		}
+		return nil
+	case <-b.done:
+		return b.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Heads in the given direction, in radians
+func (b *Bot) SendDirection(direction float64) error {
+	b.writeMux.Lock()
+	defer b.writeMux.Unlock()
+
+	b.sequence++
+	err := b.write(packets.NewPlayerDirection(direction, b.sequence, time.Now().UnixNano()))
+	if err == nil {
+		b.updateStats(func(s *Stats) { s.InputsSent++ })
+	}
+	return err
+}
+
+// Sends an input every interval, heading wherever the script says, until the
+// context is done or the connection is lost
+func (b *Bot) Run(ctx context.Context, script Script, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for {
+		if err := b.SendDirection(script.Direction(time.Since(start))); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-b.done:
+			return b.err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (b *Bot) Send(msg packets.Msg) error {
+	b.writeMux.Lock()
+	defer b.writeMux.Unlock()
+
+	return b.write(msg)
+}
+
+func (b *Bot) write(msg packets.Msg) error {
+	data, err := proto.Marshal(&packets.Packet{Msg: msg})
+	if err != nil {
+		return err
+	}
+	b.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := b.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		return err
+	}
+	b.updateStats(func(s *Stats) { s.PacketsSent++ })
+	return nil
+}
+
+// Says goodbye to the server and waits for the connection to end
+func (b *Bot) Close() error {
+	b.writeMux.Lock()
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	err := b.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(writeWait))
+	b.writeMux.Unlock()
+
+	select {
+	case <-b.done:
+	case <-time.After(writeWait):
+	}
+	b.conn.Close()
+	return err
+}
+
+func (b *Bot) readLoop() {
+	defer close(b.done)
+
+	for {
+		_, data, err := b.conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) && closeErr.Code != websocket.CloseNormalClosure {
+				b.err = fmt.Errorf("%w: %v", ErrClosed, closeErr)
+			} else {
+				b.err = ErrClosed
+			}
+			return
+		}
+
+		// The server follows every packet with a newline
+		data = bytes.TrimSuffix(data, []byte{'\n'})
+
+		packet := &packets.Packet{}
+		if err := proto.Unmarshal(data, packet); err != nil {
+			b.err = fmt.Errorf("decoding packet: %w", err)
+			b.conn.Close()
+			return
+		}
+		b.updateStats(func(s *Stats) { s.PacketsReceived++ })
+		b.handle(packet)
+	}
+}
+
+func (b *Bot) handle(packet *packets.Packet) {
+	switch msg := packet.Msg.(type) {
+	case *packets.Packet_Id:
+		// The first id we're sent is our own, the rest are players leaving
+		if b.id.CompareAndSwap(0, msg.Id.Id) {
+			close(b.gotId)
+		}
+	case *packets.Packet_Ping:
+		go b.Send(packets.NewPong(msg.Ping.Timestamp))
+	case *packets.Packet_Pong:
+		rtt := time.Since(time.Unix(0, msg.Pong.Timestamp))
+		b.updateStats(func(s *Stats) {
+			s.RttSamples++
+			s.RttTotal += rtt
+			s.RttMax = max(s.RttMax, rtt)
+		})
+	case *packets.Packet_Player:
+		if msg.Player.Id == b.Id() {
+			b.updateStats(func(s *Stats) {
+				s.OwnUpdates++
+				s.LastAcked = max(s.LastAcked, msg.Player.LastInputSequence)
+			})
+		} else {
+			b.updateStats(func(s *Stats) { s.OtherUpdates++ })
+		}
+	case *packets.Packet_OkResponse, *packets.Packet_DenyResponse:
+		select {
+		case b.responses <- msg:
+		default:
+			// Nobody asked
+		}
+	}
+}
+
+func (b *Bot) pingLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.Send(packets.NewPing(time.Now().UnixNano()))
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *Bot) updateStats(update func(s *Stats)) {
+	b.statsMux.Lock()
+	defer b.statsMux.Unlock()
+
+	update(&b.stats)
+}
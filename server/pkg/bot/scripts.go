@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Decides which way a bot heads, given how long it has been moving
+type Script interface {
+	Direction(elapsed time.Duration) float64
+}
+
+type ScriptFunc func(elapsed time.Duration) float64
+
+func (f ScriptFunc) Direction(elapsed time.Duration) float64 {
+	return f(elapsed)
+}
+
+// Always heads the same way, ending up against the edge of the map
+func Straight(direction float64) Script {
+	return ScriptFunc(func(time.Duration) float64 {
+		return direction
+	})
+}
+
+// Turns steadily, going all the way round once per period
+func Circle(period time.Duration) Script {
+	return ScriptFunc(func(elapsed time.Duration) float64 {
+		return 2 * math.Pi * elapsed.Seconds() / period.Seconds()
+	})
+}
+
+// Picks a new random direction every so often
+func Wander(rng *rand.Rand, turnEvery time.Duration) Script {
+	direction := rng.Float64() * 2 * math.Pi
+	var lastTurn time.Duration
+	return ScriptFunc(func(elapsed time.Duration) float64 {
+		if elapsed-lastTurn >= turnEvery {
+			direction = rng.Float64() * 2 * math.Pi
+			lastTurn = elapsed
+		}
+		return direction
+	})
+}
@@ -1,6 +1,9 @@
 package packets
 
-import "server/internal/server/objects"
+import (
+	"server/internal/server/objects"
+	"time"
+)
 
 type Msg = isPacket_Msg
 
@@ -42,6 +45,165 @@ func NewPlayer(id uint64, player *objects.Player) Msg {
 			Radius:    player.Radius,
 			Direction: player.Direction,
 			Speed:     player.Speed,
+			RttMs:     uint32(player.Rtt.Milliseconds()),
 		},
 	}
 }
+
+// Like NewPlayer, but also tells the owning client which of its inputs have been
+// applied, so it can reconcile its predicted position
+func NewOwnPlayer(id uint64, player *objects.Player, lastInputSequence uint32, lastInputTimestamp int64) Msg {
+	msg := NewPlayer(id, player).(*Packet_Player)
+	msg.Player.LastInputSequence = lastInputSequence
+	msg.Player.LastInputTimestamp = lastInputTimestamp
+	return msg
+}
+
+func NewSpore(id uint64, spore *objects.Spore) Msg {
+	return &Packet_Spore{
+		Spore: &SporeMessage{
+			Id:     id,
+			X:      spore.X,
+			Y:      spore.Y,
+			Radius: spore.Radius,
+		},
+	}
+}
+
+func NewSporeConsumed(sporeId uint64) Msg {
+	return &Packet_SporeConsumed{
+		SporeConsumed: &SporeConsumedMessage{
+			SporeId: sporeId,
+		},
+	}
+}
+
+func NewPlayerConsumed(playerId uint64) Msg {
+	return &Packet_PlayerConsumed{
+		PlayerConsumed: &PlayerConsumedMessage{
+			PlayerId: playerId,
+		},
+	}
+}
+
+func NewPing(timestamp int64) Msg {
+	return &Packet_Ping{
+		Ping: &PingMessage{
+			Timestamp: timestamp,
+		},
+	}
+}
+
+func NewPong(timestamp int64) Msg {
+	return &Packet_Pong{
+		Pong: &PongMessage{
+			Timestamp: timestamp,
+		},
+	}
+}
+
+func NewLoginRequest(username, password string) Msg {
+	return &Packet_LoginRequest{
+		LoginRequest: &LoginRequestMessage{
+			Username: username,
+			Password: password,
+		},
+	}
+}
+
+func NewRegisterRequest(username, password string) Msg {
+	return &Packet_RegisterRequest{
+		RegisterRequest: &RegisterRequestMessage{
+			Username: username,
+			Password: password,
+		},
+	}
+}
+
+func NewPlayerDirection(direction float64, sequence uint32, timestamp int64) Msg {
+	return &Packet_PlayerDirection{
+		PlayerDirection: &PlayerDirectionMessage{
+			Direction: direction,
+			Sequence:  sequence,
+			Timestamp: timestamp,
+		},
+	}
+}
+
+func NewSpectate(mode SpectateMessage_Mode, playerId uint64) Msg {
+	return &Packet_Spectate{
+		Spectate: &SpectateMessage{
+			Mode:     mode,
+			PlayerId: playerId,
+		},
+	}
+}
+
+func NewRoomListRequest() Msg {
+	return &Packet_RoomListRequest{
+		RoomListRequest: &RoomListRequestMessage{},
+	}
+}
+
+func NewRoomList(rooms []*RoomInfo) Msg {
+	return &Packet_RoomList{
+		RoomList: &RoomListMessage{
+			Rooms: rooms,
+		},
+	}
+}
+
+func NewJoinRoom(roomId uint64) Msg {
+	return &Packet_JoinRoom{
+		JoinRoom: &JoinRoomMessage{
+			RoomId: roomId,
+		},
+	}
+}
+
+func NewLeaveRoom() Msg {
+	return &Packet_LeaveRoom{
+		LeaveRoom: &LeaveRoomMessage{},
+	}
+}
+
+func NewJoinQueue() Msg {
+	return &Packet_JoinQueue{
+		JoinQueue: &JoinQueueMessage{},
+	}
+}
+
+func NewLeaveQueue() Msg {
+	return &Packet_LeaveQueue{
+		LeaveQueue: &LeaveQueueMessage{},
+	}
+}
+
+func NewQueueStatus(waiting int, rating, ratingGap float64, waited time.Duration) Msg {
+	return &Packet_QueueStatus{
+		QueueStatus: &QueueStatusMessage{
+			Waiting:       uint32(waiting),
+			Rating:        rating,
+			RatingGap:     ratingGap,
+			WaitedSeconds: uint32(waited / time.Second),
+		},
+	}
+}
+
+func NewMatchResult(placements []*MatchPlacement) Msg {
+	return &Packet_MatchResult{
+		MatchResult: &MatchResultMessage{
+			Placements: placements,
+		},
+	}
+}
+
+// The name of the message's field in the packet, e.g. "player_direction"
+func MsgName(msg Msg) string {
+	packet := (&Packet{Msg: msg}).ProtoReflect()
+	field := packet.WhichOneof(packet.Descriptor().Oneofs().ByName("msg"))
+	if field == nil {
+		return "none"
+	}
+	return string(field.Name())
+}
@@ -0,0 +1,217 @@
+// Reads and writes replay files: everything the world broadcast during a game,
+// tick by tick, so it can be watched again or picked apart later.
+//
+// A file starts with the magic bytes "RRREPLAY", a uvarint format version and
+// a uvarint-length-prefixed JSON Header. Then come the records until the end
+// of the file, each a uvarint tick, a uvarint kind, a uvarint length and that
+// many bytes: a protobuf Packet, or the JSON Tuning the game switched to. Files
+// from version 1 have no kinds, every record is a packet.
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"server/pkg/packets"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	magic   = "RRREPLAY"
+	Version = 2
+
+	// Anything bigger than this is a corrupt file rather than a real packet
+	maxRecordSize = 1 << 20
+)
+
+var ErrNotReplay = errors.New("not a replay file")
+
+// What's needed to make sense of the records. The tick interval and map size
+// are what the game started with, Tuning records say when they changed.
+type Header struct {
+	Version      uint64        `json:"version"`
+	Seed         int64         `json:"seed"`
+	TickInterval time.Duration `json:"tick_interval"`
+	MapSize      float64       `json:"map_size"`
+	StartedAt    time.Time     `json:"started_at"`
+}
+
+// Settings the game can change part way through, when the server reloads its
+// config
+type Tuning struct {
+	TickInterval time.Duration `json:"tick_interval"`
+	MapSize      float64       `json:"map_size"`
+}
+
+// What a record holds
+const (
+	kindPacket = iota
+	kindTuning
+)
+
+// A packet broadcast during the given tick, counting from 1, or the tuning the
+// game switched to at the start of it. Exactly one of Packet and Tuning is set.
+type Record struct {
+	Tick   uint64
+	Packet *packets.Packet
+	Tuning *Tuning
+}
+
+type Writer struct {
+	w       *bufio.Writer
+	scratch []byte
+}
+
+// Writes the header straight away. Records are buffered, so call Flush when
+// done.
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	header.Version = Version
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	rw := &Writer{w: bufio.NewWriter(w)}
+	rw.w.WriteString(magic)
+	rw.writeUvarint(Version)
+	rw.writeUvarint(uint64(len(data)))
+	rw.w.Write(data)
+	return rw, rw.w.Flush()
+}
+
+func (w *Writer) Write(tick uint64, packet *packets.Packet) error {
+	data, err := proto.MarshalOptions{}.MarshalAppend(w.scratch[:0], packet)
+	if err != nil {
+		return fmt.Errorf("encoding packet: %w", err)
+	}
+	w.scratch = data
+	return w.writeRecord(tick, kindPacket, data)
+}
+
+// Records that the game runs with the tuning from the given tick on
+func (w *Writer) WriteTuning(tick uint64, tuning Tuning) error {
+	data, err := json.Marshal(tuning)
+	if err != nil {
+		return err
+	}
+	return w.writeRecord(tick, kindTuning, data)
+}
+
+func (w *Writer) writeRecord(tick uint64, kind uint64, data []byte) error {
+	w.writeUvarint(tick)
+	w.writeUvarint(kind)
+	w.writeUvarint(uint64(len(data)))
+	_, err := w.w.Write(data)
+	return err
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+func (w *Writer) writeUvarint(x uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.w.Write(buf[:binary.PutUvarint(buf[:], x)])
+}
+
+type Reader struct {
+	r       *bufio.Reader
+	version uint64
+	header  Header
+}
+
+// Reads the header, failing with ErrNotReplay if it isn't one
+func NewReader(r io.Reader) (*Reader, error) {
+	rr := &Reader{r: bufio.NewReader(r)}
+
+	start := make([]byte, len(magic))
+	if _, err := io.ReadFull(rr.r, start); err != nil || string(start) != magic {
+		return nil, ErrNotReplay
+	}
+	version, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return nil, ErrNotReplay
+	}
+	if version < 1 || version > Version {
+		return nil, fmt.Errorf("unsupported replay version %d, expected at most %d", version, Version)
+	}
+	rr.version = version
+
+	data, err := rr.readChunk()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if err := json.Unmarshal(data, &rr.header); err != nil {
+		return nil, fmt.Errorf("parsing header: %w", err)
+	}
+	return rr, nil
+}
+
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Returns the next record, or io.EOF once there are none left. A file that
+// ends part way through a record (e.g. because the server crashed) gives
+// io.ErrUnexpectedEOF.
+func (r *Reader) Next() (Record, error) {
+	tick, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return Record{}, err
+	}
+	kind := uint64(kindPacket)
+	if r.version >= 2 {
+		if kind, err = binary.ReadUvarint(r.r); err != nil {
+			return Record{}, noEOF(err)
+		}
+	}
+
+	data, err := r.readChunk()
+	if err != nil {
+		return Record{}, err
+	}
+	switch kind {
+	case kindPacket:
+	case kindTuning:
+		tuning := &Tuning{}
+		if err := json.Unmarshal(data, tuning); err != nil {
+			return Record{}, fmt.Errorf("decoding tuning in tick %d: %w", tick, err)
+		}
+		return Record{Tick: tick, Tuning: tuning}, nil
+	default:
+		return Record{}, fmt.Errorf("unknown kind of record %d in tick %d", kind, tick)
+	}
+	packet := &packets.Packet{}
+	if err := proto.Unmarshal(data, packet); err != nil {
+		return Record{}, fmt.Errorf("decoding packet in tick %d: %w", tick, err)
+	}
+	return Record{Tick: tick, Packet: packet}, nil
+}
+
+func (r *Reader) readChunk() ([]byte, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("record of %d bytes is too big", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, noEOF(err)
+	}
+	return data, nil
+}
+
+// Running out part way through a record isn't a clean end
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
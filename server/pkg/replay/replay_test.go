@@ -0,0 +1,111 @@
+package replay_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"server/pkg/packets"
+	"server/pkg/replay"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func readAll(t *testing.T, data []byte) (replay.Header, []replay.Record) {
+	t.Helper()
+	reader, err := replay.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("reading header: %v", err)
+	}
+	var records []replay.Record
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return reader.Header(), records
+		}
+		if err != nil {
+			t.Fatalf("reading record %d: %v", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	header := replay.Header{Seed: 7, TickInterval: 50 * time.Millisecond, MapSize: 1000}
+	writer, err := replay.NewWriter(&buf, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat := &packets.Packet{SenderId: 3, Msg: packets.NewChat("hi")}
+	tuning := replay.Tuning{TickInterval: 100 * time.Millisecond, MapSize: 2000}
+	if err := writer.Write(1, chat); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteTuning(5, tuning); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write(6, &packets.Packet{Msg: packets.NewId(3)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	gotHeader, records := readAll(t, buf.Bytes())
+	if gotHeader.Version != replay.Version || gotHeader.Seed != 7 || gotHeader.TickInterval != header.TickInterval {
+		t.Errorf("got header %+v", gotHeader)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	if records[0].Tick != 1 || !proto.Equal(records[0].Packet, chat) || records[0].Tuning != nil {
+		t.Errorf("first record is %+v, want the chat in tick 1", records[0])
+	}
+	if records[1].Tick != 5 || records[1].Packet != nil || records[1].Tuning == nil || *records[1].Tuning != tuning {
+		t.Errorf("second record is %+v, want the tuning in tick 5", records[1])
+	}
+	if records[2].Tick != 6 || records[2].Packet.GetId().GetId() != 3 {
+		t.Errorf("third record is %+v, want the id in tick 6", records[2])
+	}
+}
+
+// Files written before tuning records have no kind in front of each record
+func TestReadsVersion1(t *testing.T) {
+	var buf bytes.Buffer
+	writeUvarint := func(x uint64) {
+		buf.Write(binary.AppendUvarint(nil, x))
+	}
+	header, _ := json.Marshal(replay.Header{Version: 1, TickInterval: 50 * time.Millisecond})
+	packet, _ := proto.Marshal(&packets.Packet{Msg: packets.NewChat("old")})
+	buf.WriteString("RRREPLAY")
+	writeUvarint(1)
+	writeUvarint(uint64(len(header)))
+	buf.Write(header)
+	writeUvarint(2)
+	writeUvarint(uint64(len(packet)))
+	buf.Write(packet)
+
+	_, records := readAll(t, buf.Bytes())
+	if len(records) != 1 || records[0].Tick != 2 || records[0].Packet.GetChat().GetMsg() != "old" {
+		t.Errorf("got records %+v, want the chat in tick 2", records)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	writer, _ := replay.NewWriter(&buf, replay.Header{})
+	writer.Write(1, &packets.Packet{Msg: packets.NewChat("cut off")})
+	writer.Flush()
+
+	reader, err := replay.NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}